@@ -0,0 +1,144 @@
+package cardsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Directory reads sets and cards from files in a local directory:
+//
+//	sets.json or sets.csv          every set: set_id, set_name
+//	{set_id}.json or {set_id}.csv  the cards of a set, in the OPTCG API fields
+//
+// JSON files hold arrays in the OPTCG API's shape, so saved API responses can
+// be used as they are. CSV files start with a header row naming the fields;
+// unknown columns are ignored. A file's modification time and size serve as
+// its ETag, so unchanged files are not read again.
+type Directory struct {
+	dir string
+}
+
+// NewDirectory creates a directory source, checking the directory exists
+func NewDirectory(dir string) (*Directory, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("card source directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("card source directory: %s is not a directory", dir)
+	}
+	return &Directory{dir: dir}, nil
+}
+
+// Name describes the source in logs
+func (s *Directory) Name() string {
+	return "directory " + s.dir
+}
+
+// ListSets reads sets.json or sets.csv
+func (s *Directory) ListSets(ctx context.Context, since Validators) (*SetList, error) {
+	var list SetList
+	var err error
+	list.Validators, list.NotModified, err = s.read("sets", since, &list.Sets, func(row map[string]string) {
+		list.Sets = append(list.Sets, Set{SetID: row["set_id"], SetName: row["set_name"]})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListCards reads {set_id}.json or {set_id}.csv
+func (s *Directory) ListCards(ctx context.Context, setID string, since Validators) (*CardList, error) {
+	if err := checkSetID(setID); err != nil {
+		return nil, err
+	}
+	var apiCards []APICard
+	validators, notModified, err := s.read(setID, since, &apiCards, func(row map[string]string) {
+		counter, _ := strconv.Atoi(row["counter_amount"])
+		apiCards = append(apiCards, APICard{
+			CardName:      row["card_name"],
+			CardSetID:     row["card_set_id"],
+			CardCost:      row["card_cost"],
+			CardPower:     row["card_power"],
+			CardColor:     row["card_color"],
+			CardType:      row["card_type"],
+			Rarity:        row["rarity"],
+			Attribute:     row["attribute"],
+			CardText:      row["card_text"],
+			CardImage:     row["card_image"],
+			SetID:         row["set_id"],
+			SetName:       row["set_name"],
+			CounterAmount: counter,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := CardList{Validators: validators, NotModified: notModified}
+	for _, c := range apiCards {
+		list.Cards = append(list.Cards, c.Card())
+	}
+	return &list, nil
+}
+
+// read decodes name.json into v, or failing that passes each row of
+// name.csv to add. When the file's ETag matches since it is not read.
+func (s *Directory) read(name string, since Validators, v interface{}, add func(row map[string]string)) (Validators, bool, error) {
+	path := filepath.Join(s.dir, name+".json")
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		path = filepath.Join(s.dir, name+".csv")
+		info, err = os.Stat(path)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return Validators{}, false, fmt.Errorf("no %s.json or %s.csv in %s", name, name, s.dir)
+	}
+	if err != nil {
+		return Validators{}, false, err
+	}
+
+	validators := Validators{ETag: fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())}
+	if since.ETag == validators.ETag {
+		return validators, true, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Validators{}, false, err
+	}
+	if filepath.Ext(path) == ".json" {
+		if err := json.Unmarshal(data, v); err != nil {
+			return Validators{}, false, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		return validators, false, nil
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return Validators{}, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return validators, false, nil
+	}
+	header := records[0]
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, field := range header {
+			if i < len(record) {
+				row[strings.TrimSpace(field)] = record[i]
+			}
+		}
+		add(row)
+	}
+	return validators, false, nil
+}
@@ -0,0 +1,147 @@
+package cardsource
+
+import (
+	"card-separator/database"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOPTCGURL is the base URL of the public OPTCG API
+const DefaultOPTCGURL = "https://optcgapi.com/api"
+
+// APICard represents a card from the OPTCG API. Directory sources read the
+// same fields.
+type APICard struct {
+	CardName      string `json:"card_name"`
+	CardSetID     string `json:"card_set_id"`
+	CardCost      string `json:"card_cost"`
+	CardPower     string `json:"card_power"`
+	CardColor     string `json:"card_color"`
+	CardType      string `json:"card_type"`
+	Rarity        string `json:"rarity"`
+	Attribute     string `json:"attribute"`
+	CardText      string `json:"card_text"`
+	CardImage     string `json:"card_image"`
+	SetID         string `json:"set_id"`
+	SetName       string `json:"set_name"`
+	CounterAmount int    `json:"counter_amount"`
+}
+
+// Card converts the API card into the cached card shape
+func (c APICard) Card() database.Card {
+	// Parse cost and power as integers
+	cost, _ := strconv.Atoi(c.CardCost)
+	power, _ := strconv.Atoi(c.CardPower)
+
+	return database.Card{
+		CardSetID:    c.CardSetID,
+		CardName:     c.CardName,
+		SetID:        c.SetID,
+		SetName:      c.SetName,
+		CardImageURL: c.CardImage,
+		CardColor:    c.CardColor,
+		CardType:     c.CardType,
+		CardCost:     cost,
+		CardPower:    power,
+		Rarity:       c.Rarity,
+		Attribute:    c.Attribute,
+		CardText:     c.CardText,
+	}
+}
+
+// OPTCG reads sets and cards from the OPTCG API
+type OPTCG struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOPTCG creates an OPTCG API source. An empty baseURL uses
+// DefaultOPTCGURL.
+func NewOPTCG(baseURL string) *OPTCG {
+	if baseURL == "" {
+		baseURL = DefaultOPTCGURL
+	}
+	return &OPTCG{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name describes the source in logs
+func (s *OPTCG) Name() string {
+	return "OPTCG API (" + s.baseURL + ")"
+}
+
+// ListSets fetches every set from /allSets/
+func (s *OPTCG) ListSets(ctx context.Context, since Validators) (*SetList, error) {
+	var list SetList
+	var err error
+	list.Validators, list.HTTPStatus, err = s.get(ctx, "/allSets/", since, &list.Sets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sets from API: %w", err)
+	}
+	list.NotModified = list.HTTPStatus == http.StatusNotModified
+	return &list, nil
+}
+
+// ListCards fetches the cards of a set from /sets/{set_id}/
+func (s *OPTCG) ListCards(ctx context.Context, setID string, since Validators) (*CardList, error) {
+	if err := checkSetID(setID); err != nil {
+		return nil, err
+	}
+	var apiCards []APICard
+	validators, status, err := s.get(ctx, "/sets/"+setID+"/", since, &apiCards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cards from API: %w", err)
+	}
+	list := CardList{Validators: validators, NotModified: status == http.StatusNotModified, HTTPStatus: status}
+	for _, c := range apiCards {
+		list.Cards = append(list.Cards, c.Card())
+	}
+	return &list, nil
+}
+
+// get decodes the JSON response of an API path into v and returns the
+// response status. The request is conditional on since; a 304 response
+// leaves v alone.
+func (s *OPTCG) get(ctx context.Context, path string, since Validators, v interface{}) (validators Validators, status int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return Validators{}, 0, err
+	}
+	if since.ETag != "" {
+		req.Header.Set("If-None-Match", since.ETag)
+	}
+	if since.LastModified != "" {
+		req.Header.Set("If-Modified-Since", since.LastModified)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return Validators{}, 0, err
+	}
+	defer resp.Body.Close()
+
+	validators = Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		// Servers may leave the validators out of a 304
+		if validators == (Validators{}) {
+			validators = since
+		}
+		return validators, resp.StatusCode, nil
+	default:
+		return Validators{}, resp.StatusCode, &StatusError{Source: "OPTCG API", Code: resp.StatusCode}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return Validators{}, resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return validators, resp.StatusCode, nil
+}
@@ -0,0 +1,103 @@
+// Package cardsource provides the set and card data the sync services cache:
+// the OPTCG API, or a local directory of JSON or CSV files for offline use.
+package cardsource
+
+import (
+	"card-separator/database"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Source kinds selectable through config.Config
+const (
+	KindOPTCG     = "optcg"
+	KindDirectory = "directory"
+)
+
+// Set is a card set as listed by a source
+type Set struct {
+	SetID   string `json:"set_id"`
+	SetName string `json:"set_name"`
+}
+
+// Validators identify the version of a listing, like HTTP's ETag and
+// Last-Modified. Passing the validators of the last listing makes a request
+// conditional.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// SetList is a listing of every set
+type SetList struct {
+	Sets        []Set
+	Validators  Validators
+	NotModified bool // Unchanged since the validators passed in; Sets is empty
+	HTTPStatus  int  // Response status of HTTP sources, 0 for others
+}
+
+// CardList is a listing of the cards in a set
+type CardList struct {
+	Cards       []database.Card
+	Validators  Validators
+	NotModified bool // Unchanged since the validators passed in; Cards is empty
+	HTTPStatus  int  // Response status of HTTP sources, 0 for others
+}
+
+// StatusError is returned by HTTP sources for responses with an unexpected
+// status
+type StatusError struct {
+	Source string
+	Code   int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code from %s: %d", e.Source, e.Code)
+}
+
+// HTTPStatus returns the response status behind a listing error, 0 if the
+// error did not come from an HTTP response
+func HTTPStatus(err error) int {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code
+	}
+	return 0
+}
+
+// CardSource lists sets and the cards in a set. Each listing takes the
+// validators of the previous one, zero for an unconditional request, and
+// reports NotModified when the source can tell nothing changed.
+type CardSource interface {
+	// Name describes the source in logs
+	Name() string
+	// ListSets returns every set the source knows
+	ListSets(ctx context.Context, since Validators) (*SetList, error)
+	// ListCards returns the cards of a set
+	ListCards(ctx context.Context, setID string, since Validators) (*CardList, error)
+}
+
+// validSetID matches set IDs safe to use in URLs and file names
+var validSetID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// New creates the source of the given kind. apiURL is the OPTCG API base
+// URL and dir the directory read by the directory source.
+func New(kind, apiURL, dir string) (CardSource, error) {
+	switch kind {
+	case KindOPTCG:
+		return NewOPTCG(apiURL), nil
+	case KindDirectory:
+		return NewDirectory(dir)
+	}
+	return nil, fmt.Errorf("unknown card source %q (want %q or %q)", kind, KindOPTCG, KindDirectory)
+}
+
+// checkSetID rejects set IDs that could escape a URL path or directory
+func checkSetID(setID string) error {
+	if !validSetID.MatchString(setID) {
+		return fmt.Errorf("invalid set ID: %q", setID)
+	}
+	return nil
+}
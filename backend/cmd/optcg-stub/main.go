@@ -0,0 +1,48 @@
+// Command optcg-stub serves recorded OPTCG API fixtures for offline
+// development. Point the backend at it with
+// OPTCG_API_URL=http://localhost:8081/api.
+package main
+
+import (
+	"card-separator/optcgstub"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	dir := flag.String("fixtures", "", "fixture directory (allSets.json, sets/{set_id}.json); bundled fixtures when empty")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	errorRate := flag.Float64("error-rate", 0, "share of requests, 0 to 1, answered with -error-status")
+	errorStatus := flag.Int("error-status", http.StatusServiceUnavailable, "status of injected errors")
+	malformedRate := flag.Float64("malformed-rate", 0, "share of requests, 0 to 1, answered with cut-off JSON")
+	flag.Parse()
+
+	fixtures := optcgstub.Fixtures()
+	if *dir != "" {
+		fixtures = os.DirFS(*dir)
+	}
+	stub := optcgstub.New(fixtures)
+	err := stub.SetFaults(optcgstub.Faults{
+		Latency:       *latency,
+		ErrorRate:     *errorRate,
+		ErrorStatus:   *errorStatus,
+		MalformedRate: *malformedRate,
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid faults: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           stub,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("🧪 OPTCG API stub listening on %s (faults: %+v)", *addr, stub.Faults())
+	log.Println("   GET /api/allSets/, GET /api/sets/{set_id}/")
+	log.Println("   GET, PUT /_stub/faults ({\"latency_ms\", \"error_rate\", \"error_status\", \"malformed_rate\"})")
+	log.Fatal(server.ListenAndServe())
+}
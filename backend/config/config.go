@@ -28,6 +28,14 @@ type Config struct {
 	// Sync
 	SetSyncInterval   time.Duration
 	AutoSyncOnStartup bool
+	CardSource        string // "optcg" or "directory"
+	OPTCGAPIURL       string // Base URL of the OPTCG API
+	CardSourceDir     string // Directory of JSON/CSV set and card files for the directory source
+
+	// Render queue
+	RenderWorkers int
+	RenderTimeout time.Duration // Longest a queued render may run
+	RenderTTL     time.Duration // How long finished files stay downloadable
 }
 
 func Load() *Config {
@@ -43,6 +51,12 @@ func Load() *Config {
 		CacheMaxAge:         time.Duration(getEnvInt("CACHE_MAX_AGE_HOURS", 168)) * time.Hour, // 7 days
 		SetSyncInterval:     time.Duration(getEnvInt("SET_SYNC_INTERVAL_HOURS", 24)) * time.Hour,
 		AutoSyncOnStartup:   getEnvBool("AUTO_SYNC_ON_STARTUP", true),
+		CardSource:          getEnv("CARD_SOURCE", "optcg"),
+		OPTCGAPIURL:         getEnv("OPTCG_API_URL", "https://optcgapi.com/api"),
+		CardSourceDir:       getEnv("CARD_SOURCE_DIR", "./data/cards"),
+		RenderWorkers:       getEnvInt("RENDER_WORKERS", 2),
+		RenderTimeout:       time.Duration(getEnvInt("RENDER_TIMEOUT_MINUTES", 10)) * time.Minute,
+		RenderTTL:           time.Duration(getEnvInt("RENDER_TTL_HOURS", 24)) * time.Hour,
 		ImageSizes: map[string]int{
 			"thumbnail": 300,
 			"medium":    600,
@@ -1,7 +1,24 @@
 package database
 
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// execer is satisfied by both *DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
 // UpsertCard inserts or updates a card
 func (db *DB) UpsertCard(card *Card) error {
+	return upsertCard(db, card)
+}
+
+// upsertCard inserts or updates a card, reinstating it if it was retired
+func upsertCard(ex execer, card *Card) error {
 	query := `
 		INSERT INTO cards (
 			card_set_id, card_name, set_id, set_name, card_image_url,
@@ -9,6 +26,7 @@ func (db *DB) UpsertCard(card *Card) error {
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(card_set_id) DO UPDATE SET
 			card_name = excluded.card_name,
+			set_id = excluded.set_id,
 			set_name = excluded.set_name,
 			card_image_url = excluded.card_image_url,
 			card_color = excluded.card_color,
@@ -17,9 +35,10 @@ func (db *DB) UpsertCard(card *Card) error {
 			card_power = excluded.card_power,
 			rarity = excluded.rarity,
 			attribute = excluded.attribute,
-			card_text = excluded.card_text
+			card_text = excluded.card_text,
+			retired_at = NULL
 	`
-	_, err := db.Exec(query,
+	_, err := ex.Exec(query,
 		card.CardSetID, card.CardName, card.SetID, card.SetName, card.CardImageURL,
 		card.CardColor, card.CardType, card.CardCost, card.CardPower,
 		card.Rarity, card.Attribute, card.CardText,
@@ -27,13 +46,65 @@ func (db *DB) UpsertCard(card *Card) error {
 	return err
 }
 
+// ReplaceSetCards makes cards the full card list of a set in one
+// transaction. Listed cards are upserted, the set's other cards are retired
+// and the set's card count is updated. Any failure rolls the whole
+// replacement back, and so does rollback once everything has been applied,
+// so a set is never left half-synced. It returns how many cards were retired.
+func (db *DB) ReplaceSetCards(setID string, cards []Card, rollback bool) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	keep := make([]interface{}, 0, len(cards)+2)
+	keep = append(keep, time.Now(), setID)
+	for i := range cards {
+		if err := upsertCard(tx, &cards[i]); err != nil {
+			return 0, fmt.Errorf("failed to upsert card %s: %w", cards[i].CardSetID, err)
+		}
+		keep = append(keep, cards[i].CardSetID)
+	}
+
+	query := `UPDATE cards SET retired_at = ? WHERE set_id = ? AND retired_at IS NULL`
+	if len(cards) > 0 {
+		query += ` AND card_set_id NOT IN (?` + strings.Repeat(", ?", len(cards)-1) + `)`
+	}
+	res, err := tx.Exec(query, keep...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retire cards: %w", err)
+	}
+	retired, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to retire cards: %w", err)
+	}
+
+	query = `
+		UPDATE sets SET card_count = (
+			SELECT COUNT(*) FROM cards WHERE set_id = ? AND retired_at IS NULL
+		) WHERE set_id = ?
+	`
+	if _, err := tx.Exec(query, setID, setID); err != nil {
+		return 0, fmt.Errorf("failed to update set card count: %w", err)
+	}
+
+	if rollback {
+		return int(retired), nil
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int(retired), nil
+}
+
 // GetCardsBySet retrieves all cards for a specific set
 func (db *DB) GetCardsBySet(setID string) ([]Card, error) {
 	query := `
 		SELECT id, card_set_id, card_name, set_id, set_name, card_image_url,
 		       card_color, card_type, card_cost, card_power, rarity, attribute, card_text, created_at
 		FROM cards
-		WHERE set_id = ?
+		WHERE set_id = ? AND retired_at IS NULL
 		ORDER BY card_set_id
 	`
 	rows, err := db.Query(query, setID)
@@ -63,7 +134,7 @@ func (db *DB) SearchCards(color, cardType, rarity string, limit, offset int) ([]
 		SELECT id, card_set_id, card_name, set_id, set_name, card_image_url,
 		       card_color, card_type, card_cost, card_power, rarity, attribute, card_text, created_at
 		FROM cards
-		WHERE 1=1
+		WHERE retired_at IS NULL
 	`
 	args := []interface{}{}
 
@@ -107,7 +178,55 @@ func (db *DB) SearchCards(color, cardType, rarity string, limit, offset int) ([]
 // CountCardsBySet counts cards in a set
 func (db *DB) CountCardsBySet(setID string) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM cards WHERE set_id = ?`
+	query := `SELECT COUNT(*) FROM cards WHERE set_id = ? AND retired_at IS NULL`
 	err := db.QueryRow(query, setID).Scan(&count)
 	return count, err
 }
+
+// GetCardsByIDs retrieves cards by card_set_id, in the order requested.
+// IDs that are not cached or have been retired are skipped.
+func (db *DB) GetCardsByIDs(cardSetIDs []string) ([]Card, error) {
+	if len(cardSetIDs) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT id, card_set_id, card_name, set_id, set_name, card_image_url,
+		       card_color, card_type, card_cost, card_power, rarity, attribute, card_text, created_at
+		FROM cards
+		WHERE retired_at IS NULL AND card_set_id IN (?` + strings.Repeat(", ?", len(cardSetIDs)-1) + `)
+	`
+	args := make([]interface{}, len(cardSetIDs))
+	for i, id := range cardSetIDs {
+		args[i] = id
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[string]Card, len(cardSetIDs))
+	for rows.Next() {
+		var card Card
+		if err := rows.Scan(
+			&card.ID, &card.CardSetID, &card.CardName, &card.SetID, &card.SetName,
+			&card.CardImageURL, &card.CardColor, &card.CardType, &card.CardCost,
+			&card.CardPower, &card.Rarity, &card.Attribute, &card.CardText, &card.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		byID[card.CardSetID] = card
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cards := make([]Card, 0, len(cardSetIDs))
+	for _, id := range cardSetIDs {
+		if card, ok := byID[id]; ok {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
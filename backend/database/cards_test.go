@@ -0,0 +1,248 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+// newTestDB opens an initialized in-memory database private to the test
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	sqlDB, err := sql.Open("sqlite", "file:"+name+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One connection keeps every query on the same in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db := &DB{sqlDB}
+	if err := db.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// seedSet caches a set with cards, committed through ReplaceSetCards
+func seedSet(t *testing.T, db *DB, setID string, cards ...Card) {
+	t.Helper()
+	if err := db.UpsertSet(setID, "Set "+setID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ReplaceSetCards(setID, cards, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCard(setID, id, name string) Card {
+	return Card{CardSetID: id, CardName: name, SetID: setID, SetName: "Set " + setID}
+}
+
+// cardState returns a card's name and whether it is retired, failing if
+// the row is gone
+func cardState(t *testing.T, db *DB, id string) (string, bool) {
+	t.Helper()
+	var name string
+	var retired sql.NullTime
+	err := db.QueryRow(`SELECT card_name, retired_at FROM cards WHERE card_set_id = ?`, id).Scan(&name, &retired)
+	if err != nil {
+		t.Fatalf("card %s: %v", id, err)
+	}
+	return name, retired.Valid
+}
+
+func setCardCount(t *testing.T, db *DB, setID string) int {
+	t.Helper()
+	set, err := db.GetSet(setID)
+	if err != nil || set == nil {
+		t.Fatalf("set %s: %v", setID, err)
+	}
+	return set.CardCount
+}
+
+func liveCardIDs(t *testing.T, db *DB, setID string) string {
+	t.Helper()
+	cards, err := db.GetCardsBySet(setID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(cards))
+	for i, c := range cards {
+		ids[i] = c.CardSetID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestReplaceSetCardsRetiresMissing(t *testing.T) {
+	db := newTestDB(t)
+	seedSet(t, db, "OP-01",
+		testCard("OP-01", "OP01-001", "Zoro"),
+		testCard("OP-01", "OP01-002", "Luffy"),
+		testCard("OP-01", "OP01-003", "Nami"),
+	)
+
+	// OP01-002 is dropped and OP01-003 renumbered to OP01-004
+	retired, err := db.ReplaceSetCards("OP-01", []Card{
+		testCard("OP-01", "OP01-001", "Roronoa Zoro"),
+		testCard("OP-01", "OP01-004", "Nami"),
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retired != 2 {
+		t.Errorf("retired %d cards, want 2", retired)
+	}
+
+	for id, want := range map[string]bool{"OP01-001": false, "OP01-002": true, "OP01-003": true, "OP01-004": false} {
+		if _, got := cardState(t, db, id); got != want {
+			t.Errorf("card %s retired = %v, want %v", id, got, want)
+		}
+	}
+	if name, _ := cardState(t, db, "OP01-001"); name != "Roronoa Zoro" {
+		t.Errorf("card OP01-001 name = %q, want the updated name", name)
+	}
+	if got := liveCardIDs(t, db, "OP-01"); got != "OP01-001,OP01-004" {
+		t.Errorf("live cards = %s", got)
+	}
+	if got := setCardCount(t, db, "OP-01"); got != 2 {
+		t.Errorf("card_count = %d, want 2", got)
+	}
+	if n, _ := db.CountCardsBySet("OP-01"); n != 2 {
+		t.Errorf("CountCardsBySet = %d, want 2", n)
+	}
+	if cards, _ := db.GetCardsByIDs([]string{"OP01-002", "OP01-004"}); len(cards) != 1 || cards[0].CardSetID != "OP01-004" {
+		t.Errorf("GetCardsByIDs returned retired cards: %+v", cards)
+	}
+
+	// Retiring again counts only newly retired cards
+	retired, err = db.ReplaceSetCards("OP-01", []Card{testCard("OP-01", "OP01-001", "Roronoa Zoro")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retired != 1 {
+		t.Errorf("second replacement retired %d cards, want 1", retired)
+	}
+}
+
+func TestReplaceSetCardsLeavesOtherSets(t *testing.T) {
+	db := newTestDB(t)
+	seedSet(t, db, "OP-01", testCard("OP-01", "OP01-001", "Zoro"))
+	seedSet(t, db, "OP-02", testCard("OP-02", "OP02-001", "Edward Newgate"))
+
+	if _, err := db.ReplaceSetCards("OP-01", []Card{testCard("OP-01", "OP01-002", "Luffy")}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, retired := cardState(t, db, "OP02-001"); retired {
+		t.Error("replacing OP-01 retired a card of OP-02")
+	}
+	if got := setCardCount(t, db, "OP-02"); got != 1 {
+		t.Errorf("OP-02 card_count = %d, want 1", got)
+	}
+}
+
+func TestReplaceSetCardsDryRunRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	seedSet(t, db, "OP-01",
+		testCard("OP-01", "OP01-001", "Zoro"),
+		testCard("OP-01", "OP01-002", "Luffy"),
+	)
+
+	retired, err := db.ReplaceSetCards("OP-01", []Card{
+		testCard("OP-01", "OP01-001", "Roronoa Zoro"),
+		testCard("OP-01", "OP01-003", "Nami"),
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retired != 1 {
+		t.Errorf("dry run reported %d retired, want 1", retired)
+	}
+
+	if got := liveCardIDs(t, db, "OP-01"); got != "OP01-001,OP01-002" {
+		t.Errorf("live cards after dry run = %s", got)
+	}
+	if name, _ := cardState(t, db, "OP01-001"); name != "Zoro" {
+		t.Errorf("dry run updated card OP01-001 to %q", name)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cards WHERE card_set_id = 'OP01-003'`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("dry run inserted OP01-003 (%d rows, %v)", n, err)
+	}
+	if got := setCardCount(t, db, "OP-01"); got != 2 {
+		t.Errorf("card_count after dry run = %d, want 2", got)
+	}
+}
+
+func TestReplaceSetCardsIsAtomic(t *testing.T) {
+	db := newTestDB(t)
+	seedSet(t, db, "OP-01",
+		testCard("OP-01", "OP01-001", "Zoro"),
+		testCard("OP-01", "OP01-002", "Luffy"),
+	)
+	if _, err := db.Exec(`
+		CREATE TRIGGER fail_bad_card BEFORE INSERT ON cards
+		WHEN NEW.card_set_id = 'OP01-BAD'
+		BEGIN SELECT RAISE(ABORT, 'bad card'); END
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := db.ReplaceSetCards("OP-01", []Card{
+		testCard("OP-01", "OP01-001", "Roronoa Zoro"),
+		testCard("OP-01", "OP01-003", "Nami"),
+		testCard("OP-01", "OP01-BAD", "Broken"),
+	}, false)
+	if err == nil {
+		t.Fatal("expected the failing card to fail the replacement")
+	}
+	if !strings.Contains(err.Error(), "OP01-BAD") {
+		t.Errorf("error does not name the failing card: %v", err)
+	}
+
+	if got := liveCardIDs(t, db, "OP-01"); got != "OP01-001,OP01-002" {
+		t.Errorf("live cards after failed replacement = %s", got)
+	}
+	if name, _ := cardState(t, db, "OP01-001"); name != "Zoro" {
+		t.Errorf("failed replacement kept the update of OP01-001 to %q", name)
+	}
+	if got := setCardCount(t, db, "OP-01"); got != 2 {
+		t.Errorf("card_count after failed replacement = %d, want 2", got)
+	}
+}
+
+func TestReplaceSetCardsReinstatesRetired(t *testing.T) {
+	db := newTestDB(t)
+	seedSet(t, db, "OP-01",
+		testCard("OP-01", "OP01-001", "Zoro"),
+		testCard("OP-01", "OP01-002", "Luffy"),
+	)
+	if _, err := db.ReplaceSetCards("OP-01", []Card{testCard("OP-01", "OP01-001", "Zoro")}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, retired := cardState(t, db, "OP01-002"); !retired {
+		t.Fatal("OP01-002 was not retired")
+	}
+
+	retired, err := db.ReplaceSetCards("OP-01", []Card{
+		testCard("OP-01", "OP01-001", "Zoro"),
+		testCard("OP-01", "OP01-002", "Monkey D. Luffy"),
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retired != 0 {
+		t.Errorf("retired %d cards, want 0", retired)
+	}
+	name, isRetired := cardState(t, db, "OP01-002")
+	if isRetired {
+		t.Error("OP01-002 is still retired after the source listed it again")
+	}
+	if name != "Monkey D. Luffy" {
+		t.Errorf("reinstated card name = %q", name)
+	}
+	if got := setCardCount(t, db, "OP-01"); got != 2 {
+		t.Errorf("card_count = %d, want 2", got)
+	}
+}
@@ -67,6 +67,7 @@ func (db *DB) Initialize() error {
 		attribute TEXT,
 		card_text TEXT,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		retired_at TIMESTAMP,
 		FOREIGN KEY (set_id) REFERENCES sets(set_id) ON DELETE CASCADE
 	);
 
@@ -83,6 +84,94 @@ func (db *DB) Initialize() error {
 		UNIQUE(url_hash, image_size)
 	);
 
+	-- Saved layouts table
+	CREATE TABLE IF NOT EXISTS layouts (
+		id TEXT PRIMARY KEY,
+		request TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Printer duplex offset profiles table
+	CREATE TABLE IF NOT EXISTS printer_profiles (
+		name TEXT PRIMARY KEY,
+		offset_x_mm REAL NOT NULL DEFAULT 0,
+		offset_y_mm REAL NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Uploaded tab fonts table, file data lives in MinIO
+	CREATE TABLE IF NOT EXISTS fonts (
+		name TEXT PRIMARY KEY,
+		family TEXT,
+		minio_object_key TEXT NOT NULL,
+		file_size_bytes INTEGER,
+		sha256 TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Print presets table
+	CREATE TABLE IF NOT EXISTS presets (
+		name TEXT PRIMARY KEY,
+		config TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Saved print jobs table, snapshotting cards and config for reprints
+	CREATE TABLE IF NOT EXISTS print_jobs (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		request TEXT NOT NULL,
+		card_count INTEGER NOT NULL,
+		card_data_version TEXT NOT NULL,
+		font_sha256 TEXT NOT NULL DEFAULT '',
+		back_offset_x_mm REAL NOT NULL DEFAULT 0,
+		back_offset_y_mm REAL NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Custom page and separator dimension profiles table
+	CREATE TABLE IF NOT EXISTS dimension_profiles (
+		kind TEXT NOT NULL,
+		name TEXT NOT NULL,
+		width_mm REAL NOT NULL,
+		height_mm REAL NOT NULL,
+		tab_height_mm REAL NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (kind, name)
+	);
+
+	-- Per-scope sync validators and payload hashes for conditional syncs
+	CREATE TABLE IF NOT EXISTS sync_state (
+		scope TEXT PRIMARY KEY,
+		etag TEXT NOT NULL DEFAULT '',
+		last_modified TEXT NOT NULL DEFAULT '',
+		content_hash TEXT NOT NULL DEFAULT '',
+		item_count INTEGER NOT NULL DEFAULT 0,
+		checked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Sync run history, successful or not
+	CREATE TABLE IF NOT EXISTS sync_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trigger_type TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NOT NULL,
+		dry_run BOOLEAN NOT NULL DEFAULT 0,
+		not_modified BOOLEAN NOT NULL DEFAULT 0,
+		added INTEGER NOT NULL DEFAULT 0,
+		updated INTEGER NOT NULL DEFAULT 0,
+		unchanged INTEGER NOT NULL DEFAULT 0,
+		removed INTEGER NOT NULL DEFAULT 0,
+		http_status INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT ''
+	);
+
 	-- Performance indexes
 	CREATE INDEX IF NOT EXISTS idx_cards_set_id ON cards(set_id);
 	CREATE INDEX IF NOT EXISTS idx_cards_color ON cards(card_color);
@@ -90,6 +179,7 @@ func (db *DB) Initialize() error {
 	CREATE INDEX IF NOT EXISTS idx_cards_rarity ON cards(rarity);
 	CREATE INDEX IF NOT EXISTS idx_images_hash_size ON images(url_hash, image_size);
 	CREATE INDEX IF NOT EXISTS idx_sets_last_synced ON sets(last_synced);
+	CREATE INDEX IF NOT EXISTS idx_sync_runs_started_at ON sync_runs(started_at);
 	`
 
 	_, err := db.Exec(schema)
@@ -97,6 +187,17 @@ func (db *DB) Initialize() error {
 		return fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
+	// Columns added after the first release, for databases created before them
+	if err := db.addColumn("cards", "retired_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := db.addColumn("fonts", "sha256", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := db.addColumn("print_jobs", "font_sha256", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
 	// Set SQLite pragmas for performance
 	pragmas := []string{
 		"PRAGMA journal_mode=WAL",
@@ -113,3 +214,36 @@ func (db *DB) Initialize() error {
 
 	return nil
 }
+
+// addColumn adds a column to an existing table unless it already has it
+func (db *DB) addColumn(table, column, decl string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    bool
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UpsertFont inserts or replaces an uploaded font's record
+func (db *DB) UpsertFont(font *Font) error {
+	query := `
+		INSERT INTO fonts (name, family, minio_object_key, file_size_bytes, sha256, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			family = excluded.family,
+			minio_object_key = excluded.minio_object_key,
+			file_size_bytes = excluded.file_size_bytes,
+			sha256 = excluded.sha256,
+			updated_at = excluded.updated_at
+	`
+	_, err := db.Exec(query, font.Name, font.Family, font.MinioObjectKey, font.FileSizeBytes, font.SHA256, time.Now())
+	return err
+}
+
+// GetFont retrieves an uploaded font's record by name
+func (db *DB) GetFont(name string) (*Font, error) {
+	query := `SELECT name, COALESCE(family, ''), minio_object_key, COALESCE(file_size_bytes, 0), sha256, created_at, updated_at FROM fonts WHERE name = ?`
+	var font Font
+	err := db.QueryRow(query, name).Scan(
+		&font.Name,
+		&font.Family,
+		&font.MinioObjectKey,
+		&font.FileSizeBytes,
+		&font.SHA256,
+		&font.CreatedAt,
+		&font.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &font, nil
+}
+
+// GetAllFonts retrieves every uploaded font's record
+func (db *DB) GetAllFonts() ([]Font, error) {
+	query := `SELECT name, COALESCE(family, ''), minio_object_key, COALESCE(file_size_bytes, 0), sha256, created_at, updated_at FROM fonts ORDER BY name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fonts []Font
+	for rows.Next() {
+		var f Font
+		if err := rows.Scan(&f.Name, &f.Family, &f.MinioObjectKey, &f.FileSizeBytes, &f.SHA256, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		fonts = append(fonts, f)
+	}
+	return fonts, rows.Err()
+}
+
+// DeleteFont removes an uploaded font's record, reporting whether it existed
+func (db *DB) DeleteFont(name string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM fonts WHERE name = ?`, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// FontVersionInUse reports whether a font file, by SHA-256, is the current
+// version of an uploaded font or is pinned by a saved print job
+func (db *DB) FontVersionInUse(sha256 string) (bool, error) {
+	var inUse bool
+	err := db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM fonts WHERE sha256 = ?)
+			OR EXISTS(SELECT 1 FROM print_jobs WHERE font_sha256 = ?)
+	`, sha256, sha256).Scan(&inUse)
+	return inUse, err
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFontVersionInUse(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.UpsertFont(&Font{Name: "Mine", MinioObjectKey: "fonts/aaa.ttf", SHA256: "aaa"}); err != nil {
+		t.Fatal(err)
+	}
+	job := &PrintJob{ID: "job1", Name: "Job", Request: []byte("{}"), CardDataVersion: "v", FontSHA256: "aaa", CreatedAt: time.Now()}
+	if err := db.SavePrintJob(job); err != nil {
+		t.Fatal(err)
+	}
+
+	inUse := func(version string) bool {
+		t.Helper()
+		ok, err := db.FontVersionInUse(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	if !inUse("aaa") {
+		t.Error("current font version reported unused")
+	}
+	if inUse("bbb") {
+		t.Error("unknown font version reported in use")
+	}
+
+	// Replacing the font leaves the old version pinned by the job
+	if err := db.UpsertFont(&Font{Name: "Mine", MinioObjectKey: "fonts/bbb.ttf", SHA256: "bbb"}); err != nil {
+		t.Fatal(err)
+	}
+	if !inUse("aaa") || !inUse("bbb") {
+		t.Error("replaced font versions reported unused")
+	}
+
+	if _, err := db.DeletePrintJob("job1"); err != nil {
+		t.Fatal(err)
+	}
+	if inUse("aaa") {
+		t.Error("version used by no font or job reported in use")
+	}
+}
@@ -86,7 +86,7 @@ func (db *DB) GetCacheStats() (map[string]interface{}, error) {
 
 	// Total cards
 	var totalCards int
-	if err := db.QueryRow("SELECT COUNT(*) FROM cards").Scan(&totalCards); err != nil {
+	if err := db.QueryRow("SELECT COUNT(*) FROM cards WHERE retired_at IS NULL").Scan(&totalCards); err != nil {
 		return nil, err
 	}
 	stats["total_cards"] = totalCards
@@ -0,0 +1,78 @@
+package database
+
+import "database/sql"
+
+// SavePrintJob stores a print job. CreatedAt must be set by the caller as it
+// is part of the job's reproducible output.
+func (db *DB) SavePrintJob(job *PrintJob) error {
+	query := `
+		INSERT INTO print_jobs (id, name, request, card_count, card_data_version, font_sha256, back_offset_x_mm, back_offset_y_mm, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.Exec(query, job.ID, job.Name, string(job.Request), job.CardCount, job.CardDataVersion,
+		job.FontSHA256, job.BackOffsetX, job.BackOffsetY, job.CreatedAt)
+	return err
+}
+
+// GetPrintJob retrieves a print job by ID
+func (db *DB) GetPrintJob(id string) (*PrintJob, error) {
+	query := `
+		SELECT id, name, request, card_count, card_data_version, font_sha256, back_offset_x_mm, back_offset_y_mm, created_at
+		FROM print_jobs WHERE id = ?
+	`
+	var job PrintJob
+	var request string
+	err := db.QueryRow(query, id).Scan(
+		&job.ID,
+		&job.Name,
+		&request,
+		&job.CardCount,
+		&job.CardDataVersion,
+		&job.FontSHA256,
+		&job.BackOffsetX,
+		&job.BackOffsetY,
+		&job.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	job.Request = []byte(request)
+	return &job, nil
+}
+
+// GetAllPrintJobs retrieves all print jobs, newest first, without their
+// snapshotted requests
+func (db *DB) GetAllPrintJobs() ([]PrintJob, error) {
+	query := `
+		SELECT id, name, card_count, card_data_version, font_sha256, back_offset_x_mm, back_offset_y_mm, created_at
+		FROM print_jobs ORDER BY created_at DESC
+	`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []PrintJob
+	for rows.Next() {
+		var j PrintJob
+		if err := rows.Scan(&j.ID, &j.Name, &j.CardCount, &j.CardDataVersion, &j.FontSHA256, &j.BackOffsetX, &j.BackOffsetY, &j.CreatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// DeletePrintJob removes a print job, reporting whether it existed
+func (db *DB) DeletePrintJob(id string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM print_jobs WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SaveLayout stores a layout request under the given ID
+func (db *DB) SaveLayout(id string, request []byte) error {
+	query := `INSERT INTO layouts (id, request, created_at) VALUES (?, ?, ?)`
+	_, err := db.Exec(query, id, string(request), time.Now())
+	return err
+}
+
+// GetLayout retrieves a saved layout by ID
+func (db *DB) GetLayout(id string) (*SavedLayout, error) {
+	query := `SELECT id, request, created_at FROM layouts WHERE id = ?`
+	var layout SavedLayout
+	var request string
+	err := db.QueryRow(query, id).Scan(&layout.ID, &request, &layout.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	layout.Request = []byte(request)
+	return &layout, nil
+}
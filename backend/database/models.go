@@ -40,3 +40,94 @@ type Image struct {
 	CreatedAt      time.Time `json:"created_at"`
 	LastAccessed   time.Time `json:"last_accessed"`
 }
+
+// SavedLayout is a stored layout request that pages can be rendered from
+type SavedLayout struct {
+	ID        string    `json:"id"`
+	Request   []byte    `json:"-"` // JSON-encoded layout request with resolved cards
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PrinterProfile stores how far a printer misregisters the back side of a
+// duplex sheet, so renderers can shift back pages to compensate
+type PrinterProfile struct {
+	Name      string    `json:"name"`
+	OffsetX   float64   `json:"offset_x_mm"` // Positive moves back content right
+	OffsetY   float64   `json:"offset_y_mm"` // Positive moves back content down
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DimensionProfile is a user-defined page size or separator size
+type DimensionProfile struct {
+	Kind      string    `json:"kind"` // "page" or "card"
+	Name      string    `json:"name"`
+	Width     float64   `json:"width_mm"`
+	Height    float64   `json:"height_mm"`
+	TabHeight float64   `json:"tab_height_mm,omitempty"` // Separator profiles only
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Font is an uploaded tab font whose file is stored in MinIO
+type Font struct {
+	Name           string    `json:"name"`
+	Family         string    `json:"family"` // Family from the font's name table
+	MinioObjectKey string    `json:"-"`
+	FileSizeBytes  int64     `json:"file_size_bytes"`
+	SHA256         string    `json:"sha256,omitempty"` // Of the file, empty for fonts uploaded before it was recorded
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Preset is a saved print preset
+type Preset struct {
+	Name      string    `json:"name"`
+	Config    []byte    `json:"-"` // JSON-encoded preset config
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PrintJob is a saved print run whose cards, order and config are
+// snapshotted so it can be rendered again identically
+type PrintJob struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Request         []byte    `json:"-"` // JSON-encoded layout request with the snapshotted cards
+	CardCount       int       `json:"card_count"`
+	CardDataVersion string    `json:"card_data_version"`     // Hash of the snapshotted card data
+	FontSHA256      string    `json:"font_sha256,omitempty"` // Tab font file at the time of saving, empty for older jobs
+	BackOffsetX     float64   `json:"back_offset_x_mm"`      // Printer back offset at the time of saving
+	BackOffsetY     float64   `json:"back_offset_y_mm"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// SyncState remembers the last payload synced for a scope, "sets" or
+// "cards/{set_id}", so later syncs can send conditional requests and skip
+// unchanged data
+type SyncState struct {
+	Scope        string    `json:"scope"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentHash  string    `json:"content_hash"` // Hash of the converted payload
+	ItemCount    int       `json:"item_count"`   // Sets or cards cached after the sync
+	CheckedAt    time.Time `json:"checked_at"`   // Last time the source was asked
+	ChangedAt    time.Time `json:"changed_at"`   // Last time the payload differed
+}
+
+// SyncRun records one set or card sync, whether it succeeded or not
+type SyncRun struct {
+	ID          int64     `json:"id"`
+	Trigger     string    `json:"trigger"` // "startup", "ticker" or "api"
+	Scope       string    `json:"scope"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	DryRun      bool      `json:"dry_run"`
+	NotModified bool      `json:"not_modified"`
+	Added       int       `json:"added"`
+	Updated     int       `json:"updated"`
+	Unchanged   int       `json:"unchanged"`
+	Removed     int       `json:"removed"`
+	HTTPStatus  int       `json:"http_status,omitempty"` // Card source response status, 0 when there was none
+	Error       string    `json:"error,omitempty"`
+}
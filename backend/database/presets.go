@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// InsertPreset stores a new preset, reporting false if the name is taken.
+// A zero createdAt records the current time.
+func (db *DB) InsertPreset(name string, config []byte, createdAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO presets (name, config, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO NOTHING
+	`
+	now := time.Now()
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	result, err := db.Exec(query, name, string(config), createdAt, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// UpsertPreset inserts or replaces a preset's config
+func (db *DB) UpsertPreset(name string, config []byte) error {
+	query := `
+		INSERT INTO presets (name, config, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			config = excluded.config,
+			updated_at = excluded.updated_at
+	`
+	_, err := db.Exec(query, name, string(config), time.Now())
+	return err
+}
+
+// GetPreset retrieves a preset by name
+func (db *DB) GetPreset(name string) (*Preset, error) {
+	query := `SELECT name, config, created_at, updated_at FROM presets WHERE name = ?`
+	var preset Preset
+	var config string
+	err := db.QueryRow(query, name).Scan(&preset.Name, &config, &preset.CreatedAt, &preset.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	preset.Config = []byte(config)
+	return &preset, nil
+}
+
+// GetAllPresets retrieves all presets
+func (db *DB) GetAllPresets() ([]Preset, error) {
+	query := `SELECT name, config, created_at, updated_at FROM presets ORDER BY name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var presets []Preset
+	for rows.Next() {
+		var p Preset
+		var config string
+		if err := rows.Scan(&p.Name, &config, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		p.Config = []byte(config)
+		presets = append(presets, p)
+	}
+	return presets, rows.Err()
+}
+
+// DeletePreset removes a preset, reporting whether it existed
+func (db *DB) DeletePreset(name string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM presets WHERE name = ?`, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UpsertPrinterProfile inserts or updates a printer's back-side offset
+func (db *DB) UpsertPrinterProfile(profile *PrinterProfile) error {
+	query := `
+		INSERT INTO printer_profiles (name, offset_x_mm, offset_y_mm, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			offset_x_mm = excluded.offset_x_mm,
+			offset_y_mm = excluded.offset_y_mm,
+			updated_at = excluded.updated_at
+	`
+	_, err := db.Exec(query, profile.Name, profile.OffsetX, profile.OffsetY, time.Now())
+	return err
+}
+
+// GetPrinterProfile retrieves a printer profile by name
+func (db *DB) GetPrinterProfile(name string) (*PrinterProfile, error) {
+	query := `SELECT name, offset_x_mm, offset_y_mm, created_at, updated_at FROM printer_profiles WHERE name = ?`
+	var profile PrinterProfile
+	err := db.QueryRow(query, name).Scan(
+		&profile.Name,
+		&profile.OffsetX,
+		&profile.OffsetY,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// GetAllPrinterProfiles retrieves all printer profiles
+func (db *DB) GetAllPrinterProfiles() ([]PrinterProfile, error) {
+	query := `SELECT name, offset_x_mm, offset_y_mm, created_at, updated_at FROM printer_profiles ORDER BY name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []PrinterProfile
+	for rows.Next() {
+		var p PrinterProfile
+		if err := rows.Scan(&p.Name, &p.OffsetX, &p.OffsetY, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// DeletePrinterProfile removes a printer profile, reporting whether it existed
+func (db *DB) DeletePrinterProfile(name string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM printer_profiles WHERE name = ?`, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UpsertDimensionProfile inserts or updates a custom dimension profile
+func (db *DB) UpsertDimensionProfile(profile *DimensionProfile) error {
+	query := `
+		INSERT INTO dimension_profiles (kind, name, width_mm, height_mm, tab_height_mm, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(kind, name) DO UPDATE SET
+			width_mm = excluded.width_mm,
+			height_mm = excluded.height_mm,
+			tab_height_mm = excluded.tab_height_mm,
+			updated_at = excluded.updated_at
+	`
+	_, err := db.Exec(query, profile.Kind, profile.Name, profile.Width, profile.Height, profile.TabHeight, time.Now())
+	return err
+}
+
+// GetDimensionProfile retrieves a custom dimension profile by kind and name
+func (db *DB) GetDimensionProfile(kind, name string) (*DimensionProfile, error) {
+	query := `SELECT kind, name, width_mm, height_mm, tab_height_mm, created_at, updated_at FROM dimension_profiles WHERE kind = ? AND name = ?`
+	var p DimensionProfile
+	err := db.QueryRow(query, kind, name).Scan(&p.Kind, &p.Name, &p.Width, &p.Height, &p.TabHeight, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetAllDimensionProfiles retrieves every custom dimension profile by kind
+// and name
+func (db *DB) GetAllDimensionProfiles() ([]DimensionProfile, error) {
+	query := `SELECT kind, name, width_mm, height_mm, tab_height_mm, created_at, updated_at FROM dimension_profiles ORDER BY kind, name`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []DimensionProfile
+	for rows.Next() {
+		var p DimensionProfile
+		if err := rows.Scan(&p.Kind, &p.Name, &p.Width, &p.Height, &p.TabHeight, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// DeleteDimensionProfile removes a custom dimension profile, reporting
+// whether it existed
+func (db *DB) DeleteDimensionProfile(kind, name string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM dimension_profiles WHERE kind = ? AND name = ?`, kind, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -58,6 +58,13 @@ func (db *DB) GetAllSets() ([]Set, error) {
 	return sets, rows.Err()
 }
 
+// MarkSetsSynced sets last_synced of every cached set, for set listings
+// the source reports unchanged
+func (db *DB) MarkSetsSynced(at time.Time) error {
+	_, err := db.Exec(`UPDATE sets SET last_synced = ?`, at)
+	return err
+}
+
 // UpdateSetCardCount updates the card count for a set
 func (db *DB) UpdateSetCardCount(setID string, count int) error {
 	query := `UPDATE sets SET card_count = ? WHERE set_id = ?`
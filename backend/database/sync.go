@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Sync run statuses filtered on by GetSyncRuns
+const (
+	SyncRunOK     = "ok"
+	SyncRunFailed = "failed"
+)
+
+// SyncRunFilter selects sync runs. Zero fields match every run.
+type SyncRunFilter struct {
+	Trigger string
+	Scope   string // Matches the scope itself and scopes below it, "cards" matches "cards/OP-01"
+	Status  string // SyncRunOK or SyncRunFailed
+	Since   time.Time
+	Limit   int
+	Offset  int
+}
+
+// UpsertSyncState inserts or updates the sync state of a scope
+func (db *DB) UpsertSyncState(state *SyncState) error {
+	query := `
+		INSERT INTO sync_state (scope, etag, last_modified, content_hash, item_count, checked_at, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(scope) DO UPDATE SET
+			etag = excluded.etag,
+			last_modified = excluded.last_modified,
+			content_hash = excluded.content_hash,
+			item_count = excluded.item_count,
+			checked_at = excluded.checked_at,
+			changed_at = excluded.changed_at
+	`
+	_, err := db.Exec(query, state.Scope, state.ETag, state.LastModified, state.ContentHash,
+		state.ItemCount, state.CheckedAt, state.ChangedAt)
+	return err
+}
+
+// GetSyncState retrieves the sync state of a scope
+func (db *DB) GetSyncState(scope string) (*SyncState, error) {
+	query := `SELECT scope, etag, last_modified, content_hash, item_count, checked_at, changed_at FROM sync_state WHERE scope = ?`
+	var s SyncState
+	err := db.QueryRow(query, scope).Scan(&s.Scope, &s.ETag, &s.LastModified, &s.ContentHash, &s.ItemCount, &s.CheckedAt, &s.ChangedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// InsertSyncRun records a finished sync run and sets its ID
+func (db *DB) InsertSyncRun(run *SyncRun) error {
+	query := `
+		INSERT INTO sync_runs (
+			trigger_type, scope, started_at, finished_at, dry_run, not_modified,
+			added, updated, unchanged, removed, http_status, error
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := db.Exec(query, run.Trigger, run.Scope, run.StartedAt, run.FinishedAt, run.DryRun, run.NotModified,
+		run.Added, run.Updated, run.Unchanged, run.Removed, run.HTTPStatus, run.Error)
+	if err != nil {
+		return err
+	}
+	run.ID, err = res.LastInsertId()
+	return err
+}
+
+// GetSyncRuns retrieves the sync runs matching f, newest first
+func (db *DB) GetSyncRuns(f SyncRunFilter) ([]SyncRun, error) {
+	query := `
+		SELECT id, trigger_type, scope, started_at, finished_at, dry_run, not_modified,
+		       added, updated, unchanged, removed, http_status, error
+		FROM sync_runs
+		WHERE 1=1
+	`
+	args := []interface{}{}
+
+	if f.Trigger != "" {
+		query += " AND trigger_type = ?"
+		args = append(args, f.Trigger)
+	}
+	if f.Scope != "" {
+		query += " AND (scope = ? OR scope LIKE ? ESCAPE '\\')"
+		args = append(args, f.Scope, escapeLike(f.Scope)+"/%")
+	}
+	switch f.Status {
+	case SyncRunOK:
+		query += " AND error = ''"
+	case SyncRunFailed:
+		query += " AND error != ''"
+	}
+	if !f.Since.IsZero() {
+		query += " AND started_at >= ?"
+		args = append(args, f.Since.Local()) // Runs are stored in local time
+	}
+
+	query += " ORDER BY started_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []SyncRun
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// GetLastSuccessfulSyncRun retrieves the newest sync run that succeeded and
+// was not a dry run
+func (db *DB) GetLastSuccessfulSyncRun() (*SyncRun, error) {
+	query := `
+		SELECT id, trigger_type, scope, started_at, finished_at, dry_run, not_modified,
+		       added, updated, unchanged, removed, http_status, error
+		FROM sync_runs
+		WHERE error = '' AND dry_run = 0
+		ORDER BY finished_at DESC, id DESC
+		LIMIT 1
+	`
+	run, err := scanSyncRun(db.QueryRow(query))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// scanSyncRun reads a sync_runs row selected in column order
+func scanSyncRun(row interface{ Scan(...interface{}) error }) (*SyncRun, error) {
+	var r SyncRun
+	err := row.Scan(&r.ID, &r.Trigger, &r.Scope, &r.StartedAt, &r.FinishedAt, &r.DryRun, &r.NotModified,
+		&r.Added, &r.Updated, &r.Unchanged, &r.Removed, &r.HTTPStatus, &r.Error)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s, with backslash as the escape
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
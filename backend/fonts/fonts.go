@@ -0,0 +1,86 @@
+// Package fonts holds the bundled tab fonts and validates uploaded ones
+package fonts
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// Default is the bundled font used when a layout does not name one
+const Default = "Go Bold"
+
+// MaxSize is the largest font file in bytes that may be uploaded
+const MaxSize = 10 << 20
+
+// bundled are the TrueType fonts compiled into the binary
+var bundled = map[string][]byte{
+	"Go Regular":     goregular.TTF,
+	"Go Bold":        gobold.TTF,
+	"Go Italic":      goitalic.TTF,
+	"Go Bold Italic": gobolditalic.TTF,
+	"Go Medium":      gomedium.TTF,
+	"Go Mono":        gomono.TTF,
+	"Go Mono Bold":   gomonobold.TTF,
+}
+
+// validName matches font names safe to use as object keys and in URLs
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,63}$`)
+
+// Bundled returns the data of a bundled font
+func Bundled(name string) ([]byte, bool) {
+	data, ok := bundled[name]
+	return data, ok
+}
+
+// BundledNames returns the names of the bundled fonts in order
+func BundledNames() []string {
+	names := make([]string, 0, len(bundled))
+	for name := range bundled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateName checks a name can be used for an uploaded font
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("font name must be 1-64 letters, digits, spaces, '.', '_' or '-'")
+	}
+	return nil
+}
+
+// Parse checks data is a TrueType-outline font the renderers can embed and
+// returns its family name. CFF-based OpenType fonts are rejected because the
+// PDF writer cannot subset them.
+func Parse(data []byte) (family string, err error) {
+	if len(data) > MaxSize {
+		return "", fmt.Errorf("font is larger than %d MB", MaxSize>>20)
+	}
+	if bytes.HasPrefix(data, []byte("OTTO")) {
+		return "", fmt.Errorf("OpenType fonts with CFF outlines are not supported, use TrueType outlines")
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid TTF/OTF font: %w", err)
+	}
+	family, err = f.Name(nil, sfnt.NameIDFamily)
+	if err != nil {
+		// Fonts may omit the name table; the family is only informational
+		return "", nil
+	}
+	return family, nil
+}
@@ -39,22 +39,34 @@ func (h *CardHandler) GetSetCards(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(cards)
 }
 
-// SyncSetCards handles POST /api/sets/{set_id}/sync
+// SyncSetCards handles POST /api/sets/{set_id}/sync?force=true&dry_run=true.
+// Without force the sync is conditional on the last one and unchanged cards
+// are skipped. A dry run reports what the sync would change and rolls it back.
 func (h *CardHandler) SyncSetCards(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	setID := vars["set_id"]
 
-	count, err := h.syncService.SyncSetCards(setID)
+	force, ok := readBool(w, r, "force")
+	if !ok {
+		return
+	}
+	dryRun, ok := readBool(w, r, "dry_run")
+	if !ok {
+		return
+	}
+
+	result, err := h.syncService.SyncSetCards(services.SyncTriggerAPI, setID, force, dryRun)
 	if err != nil {
 		log.Printf("[API] Failed to sync cards for set %s: %v", setID, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	response := map[string]interface{}{
-		"synced_cards": count,
-		"set_id":       setID,
-	}
+	response := struct {
+		SyncedCards int    `json:"synced_cards"`
+		SetID       string `json:"set_id"`
+		*services.SyncResult
+	}{result.Total(), setID, result}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
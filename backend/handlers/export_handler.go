@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"bytes"
+	"card-separator/layout"
+	"card-separator/render"
+	"card-separator/services"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type ExportHandler struct {
+	layouts *services.LayoutService
+	pdf     *render.PDFRenderer
+	svg     *render.SVGRenderer
+	png     *render.PNGRenderer
+}
+
+func NewExportHandler(layouts *services.LayoutService, pdf *render.PDFRenderer, svg *render.SVGRenderer, png *render.PNGRenderer) *ExportHandler {
+	return &ExportHandler{
+		layouts: layouts,
+		pdf:     pdf,
+		svg:     svg,
+		png:     png,
+	}
+}
+
+// ExportSetPDF handles GET/POST /api/sets/{set_id}/separators.pdf
+// GET takes print config as query parameters, POST as a JSON body
+func (h *ExportHandler) ExportSetPDF(w http.ResponseWriter, r *http.Request) {
+	req, ok := readLayoutRequest(w, r, h.layouts)
+	if !ok {
+		return
+	}
+
+	result, err := h.layouts.BuildLayout(req)
+	if err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	writePDF(w, r, h.pdf, result, req.SetID+"-separators")
+}
+
+// writePDF renders a layout as a PDF download named name. With
+// ?manual_duplex=true a double-sided layout is split for printers without
+// duplexing: a zip of the fronts, the backs and an instruction
+// sheet, or with &part=fronts|backs|instructions just that file.
+func writePDF(w http.ResponseWriter, r *http.Request, pdf *render.PDFRenderer, result *layout.Layout, name string) {
+	q := r.URL.Query()
+	manual := false
+	if v := q.Get("manual_duplex"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid manual_duplex: %q", v), http.StatusBadRequest)
+			return
+		}
+		manual = b
+	}
+	part := render.DuplexPart(q.Get("part"))
+	if part != "" && !manual {
+		http.Error(w, "part needs manual_duplex=true", http.StatusBadRequest)
+		return
+	}
+	if part != "" && !render.ValidDuplexPart(part) {
+		http.Error(w, fmt.Sprintf("invalid part: %q", part), http.StatusBadRequest)
+		return
+	}
+	if manual && !result.Config.DoubleSided {
+		http.Error(w, "manual_duplex needs double_sided=true", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	var err error
+	contentType, filename := "application/pdf", name+".pdf"
+	switch {
+	case part != "":
+		err = pdf.RenderDuplexPart(ctx, result, part, &buf)
+		filename = fmt.Sprintf("%s-%s.pdf", name, part)
+	case manual:
+		err = pdf.RenderManualDuplex(ctx, result, &buf)
+		contentType, filename = "application/zip", name+"-manual-duplex.zip"
+	default:
+		err = pdf.Render(ctx, result, &buf)
+	}
+	if err != nil {
+		log.Printf("[API] Failed to render %s: %v", filename, err)
+		http.Error(w, "Failed to render PDF", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Write(buf.Bytes())
+}
+
+// ExportPageSVG handles GET /api/layouts/{id}/pages/{page}.svg
+func (h *ExportHandler) ExportPageSVG(w http.ResponseWriter, r *http.Request) {
+	result, pageNumber, ok := h.layoutPage(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	if err := h.svg.RenderPage(ctx, result, pageNumber, &buf); err != nil {
+		log.Printf("[API] Failed to render SVG page %d of layout %s: %v", pageNumber, result.ID, err)
+		http.Error(w, "Failed to render SVG", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(buf.Bytes())
+}
+
+// ExportPagePNG handles GET /api/layouts/{id}/pages/{page}.png?dpi=300
+func (h *ExportHandler) ExportPagePNG(w http.ResponseWriter, r *http.Request) {
+	dpi := 300
+	if v := r.URL.Query().Get("dpi"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || !render.ValidDPI(d) {
+			http.Error(w, fmt.Sprintf("Invalid dpi %q (allowed: %v)", v, render.AllowedDPIs), http.StatusBadRequest)
+			return
+		}
+		dpi = d
+	}
+
+	result, pageNumber, ok := h.layoutPage(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	if err := h.png.RenderPage(ctx, result, pageNumber, dpi, &buf); err != nil {
+		log.Printf("[API] Failed to render PNG page %d of layout %s: %v", pageNumber, result.ID, err)
+		http.Error(w, "Failed to render PNG", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// ExportPreviewPNG handles GET /api/layouts/{id}/preview.png
+// Returns every page of the layout as thumbnails on a single image
+func (h *ExportHandler) ExportPreviewPNG(w http.ResponseWriter, r *http.Request) {
+	result, err := h.layouts.GetLayout(mux.Vars(r)["id"])
+	if err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var buf bytes.Buffer
+	if err := h.png.RenderPreview(ctx, result, &buf); err != nil {
+		log.Printf("[API] Failed to render preview of layout %s: %v", result.ID, err)
+		http.Error(w, "Failed to render preview", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// ExportCutSVG handles GET /api/layouts/{id}/sheets/{sheet}/cut.svg
+// Returns the sheet's separator outlines and registration marks for a
+// cutting machine
+func (h *ExportHandler) ExportCutSVG(w http.ResponseWriter, r *http.Request) {
+	result, sheet, ok := h.layoutSheet(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render.RenderCutSVG(result, sheet, &buf); err != nil {
+		log.Printf("[API] Failed to render cut SVG for sheet %d of layout %s: %v", sheet, result.ID, err)
+		http.Error(w, "Failed to render cut SVG", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-sheet-%d-cut.svg"`, result.ID, sheet))
+	w.Write(buf.Bytes())
+}
+
+// ExportCutDXF handles GET /api/layouts/{id}/sheets/{sheet}/cut.dxf
+func (h *ExportHandler) ExportCutDXF(w http.ResponseWriter, r *http.Request) {
+	result, sheet, ok := h.layoutSheet(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render.RenderCutDXF(result, sheet, &buf); err != nil {
+		log.Printf("[API] Failed to render cut DXF for sheet %d of layout %s: %v", sheet, result.ID, err)
+		http.Error(w, "Failed to render cut DXF", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/vnd.dxf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-sheet-%d-cut.dxf"`, result.ID, sheet))
+	w.Write(buf.Bytes())
+}
+
+// layoutSheet loads the saved layout and sheet number named by the route,
+// writing an error response and returning false if either is invalid
+func (h *ExportHandler) layoutSheet(w http.ResponseWriter, r *http.Request) (*layout.Layout, int, bool) {
+	vars := mux.Vars(r)
+	sheet, err := strconv.Atoi(vars["sheet"])
+	if err != nil {
+		http.Error(w, "Invalid sheet number", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	result, err := h.layouts.GetLayout(vars["id"])
+	if err != nil {
+		writeLayoutError(w, err)
+		return nil, 0, false
+	}
+	if _, ok := result.FrontPage(sheet); !ok {
+		http.Error(w, fmt.Sprintf("Sheet %d not found (layout has %d sheets)", sheet, result.SheetCount), http.StatusNotFound)
+		return nil, 0, false
+	}
+	return result, sheet, true
+}
+
+// layoutPage loads the saved layout and page number named by the route,
+// writing an error response and returning false if either is invalid
+func (h *ExportHandler) layoutPage(w http.ResponseWriter, r *http.Request) (*layout.Layout, int, bool) {
+	vars := mux.Vars(r)
+	pageNumber, err := strconv.Atoi(vars["page"])
+	if err != nil {
+		http.Error(w, "Invalid page number", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	result, err := h.layouts.GetLayout(vars["id"])
+	if err != nil {
+		writeLayoutError(w, err)
+		return nil, 0, false
+	}
+	if _, ok := result.PageByNumber(pageNumber); !ok {
+		http.Error(w, fmt.Sprintf("Page %d not found (layout has %d pages)", pageNumber, len(result.Pages)), http.StatusNotFound)
+		return nil, 0, false
+	}
+	return result, pageNumber, true
+}
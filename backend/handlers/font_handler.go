@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"card-separator/fonts"
+	"card-separator/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type FontHandler struct {
+	service *services.FontService
+}
+
+func NewFontHandler(service *services.FontService) *FontHandler {
+	return &FontHandler{service: service}
+}
+
+// ListFonts handles GET /api/fonts
+func (h *FontHandler) ListFonts(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.ListFonts()
+	if err != nil {
+		log.Printf("[API] Failed to list fonts: %v", err)
+		http.Error(w, "Failed to list fonts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GetFont handles GET /api/fonts/{name}, returning the font file so the
+// browser can load it with @font-face
+func (h *FontHandler) GetFont(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	data, err := h.service.GetFont(r.Context(), name)
+	if err != nil {
+		writeFontError(w, name, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "font/ttf")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(data)
+}
+
+// SaveFont handles PUT /api/fonts/{name}
+// Body: the raw TTF or OTF file
+func (h *FontHandler) SaveFont(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, fonts.MaxSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("Font file must be at most %d MB", fonts.MaxSize>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to read font file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	font, err := h.service.SaveFont(r.Context(), name, data)
+	if err != nil {
+		writeFontError(w, name, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(font)
+}
+
+// DeleteFont handles DELETE /api/fonts/{name}
+func (h *FontHandler) DeleteFont(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if err := h.service.DeleteFont(r.Context(), name); err != nil {
+		writeFontError(w, name, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeFontError maps font service errors onto HTTP responses
+func writeFontError(w http.ResponseWriter, name string, err error) {
+	switch {
+	case errors.Is(err, services.ErrFontNotFound):
+		http.Error(w, "Font not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrBundledFont):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidFont):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[API] Font %s: %v", name, err)
+		http.Error(w, "Failed to process font", http.StatusInternalServerError)
+	}
+}
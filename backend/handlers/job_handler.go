@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"card-separator/database"
+	"card-separator/render"
+	"card-separator/services"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type JobHandler struct {
+	jobs    *services.PrintJobService
+	layouts *services.LayoutService
+	pdf     *render.PDFRenderer
+}
+
+func NewJobHandler(jobs *services.PrintJobService, layouts *services.LayoutService, pdf *render.PDFRenderer) *JobHandler {
+	return &JobHandler{
+		jobs:    jobs,
+		layouts: layouts,
+		pdf:     pdf,
+	}
+}
+
+// CreateJob handles POST /api/jobs
+// Body: a layout request plus "name"; the resolved cards are snapshotted
+func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	req := services.JobRequest{LayoutRequest: services.NewLayoutRequest()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.SetID == "" && len(req.SetIDs) == 0 && len(req.Cards) == 0 {
+		http.Error(w, "One of 'set_id', 'set_ids' or 'cards' is required", http.StatusBadRequest)
+		return
+	}
+	if !prepareLayoutRequest(w, h.layouts, &req.LayoutRequest) {
+		return
+	}
+
+	job, err := h.jobs.CreateJob(r.Context(), &req)
+	if err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(job)
+}
+
+// ListJobs handles GET /api/jobs
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.jobs.ListJobs()
+	if err != nil {
+		log.Printf("[API] Failed to list print jobs: %v", err)
+		http.Error(w, "Failed to list print jobs", http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []database.PrintJob{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// GetJob handles GET /api/jobs/{id}
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.GetJob(mux.Vars(r)["id"])
+	if err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// DeleteJob handles DELETE /api/jobs/{id}
+func (h *JobHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
+	if err := h.jobs.DeleteJob(r.Context(), mux.Vars(r)["id"]); err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportJobPDF handles GET /api/jobs/{id}/separators.pdf, rendering the job
+// from its snapshot so reprints match the original
+func (h *JobHandler) ExportJobPDF(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	result, err := h.jobs.BuildJobLayout(id)
+	if err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	writePDF(w, r, h.pdf, result, "job-"+id+"-separators")
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"card-separator/layout"
+	"card-separator/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type LayoutHandler struct {
+	service *services.LayoutService
+}
+
+func NewLayoutHandler(service *services.LayoutService) *LayoutHandler {
+	return &LayoutHandler{service: service}
+}
+
+// CreateLayout handles POST /api/layouts
+func (h *LayoutHandler) CreateLayout(w http.ResponseWriter, r *http.Request) {
+	req, ok := readLayoutRequest(w, r, h.service)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.CreateLayout(req)
+	if err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetLayout handles GET /api/layouts/{id}
+func (h *LayoutHandler) GetLayout(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.GetLayout(mux.Vars(r)["id"])
+	if err != nil {
+		writeLayoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// Impose handles GET/POST /api/imposition, reporting the densest grid of
+// separators for the print config without laying out any cards
+func (h *LayoutHandler) Impose(w http.ResponseWriter, r *http.Request) {
+	req := services.NewLayoutRequest()
+	if err := decodeLayoutRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !prepareLayoutRequest(w, h.service, &req) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(layout.Impose(req.ImpositionParams()))
+}
+
+// readLayoutRequest builds a layout request from the JSON body, or from query
+// parameters for GET requests. A {set_id} route variable selects the set.
+// It writes an error response and returns false if the request is unusable.
+func readLayoutRequest(w http.ResponseWriter, r *http.Request, layouts *services.LayoutService) (*services.LayoutRequest, bool) {
+	req := services.NewLayoutRequest()
+	if err := decodeLayoutRequest(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	if setID := mux.Vars(r)["set_id"]; setID != "" {
+		req.SetID = setID
+	}
+	if req.SetID == "" && len(req.SetIDs) == 0 && len(req.Cards) == 0 {
+		http.Error(w, "One of 'set_id', 'set_ids' or 'cards' is required", http.StatusBadRequest)
+		return nil, false
+	}
+	if !prepareLayoutRequest(w, layouts, &req) {
+		return nil, false
+	}
+	return &req, true
+}
+
+// prepareLayoutRequest resolves the request's profile names and validates
+// it, writing an error response and returning false if it is unusable
+func prepareLayoutRequest(w http.ResponseWriter, layouts *services.LayoutService, req *services.LayoutRequest) bool {
+	if err := layouts.ResolveProfiles(&req.PrintConfig); err != nil {
+		writeLayoutError(w, err)
+		return false
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// decodeLayoutRequest fills req from the query for GET requests, otherwise
+// from the JSON body. An empty body keeps the defaults.
+func decodeLayoutRequest(r *http.Request, req *services.LayoutRequest) error {
+	if r.Method == http.MethodGet {
+		return parseLayoutQuery(r.URL.Query(), req)
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && err != io.EOF {
+		return fmt.Errorf("Invalid request body: %v", err)
+	}
+	return nil
+}
+
+// parseLayoutQuery applies print config query parameters such as
+// ?double_sided=true&flip_edge=short&page_size=letter onto req
+func parseLayoutQuery(q url.Values, req *services.LayoutRequest) error {
+	if v := q.Get("set_id"); v != "" {
+		req.SetID = v
+	}
+	if v := q.Get("flip_edge"); v != "" {
+		req.FlipEdge = layout.FlipEdge(v)
+	}
+	if v := q.Get("image_quality"); v != "" {
+		req.ImageQuality = v
+	}
+	if v := q.Get("page_size"); v != "" {
+		req.PageSize = v
+	}
+	if v := q.Get("card_profile"); v != "" {
+		req.CardProfile = v
+	}
+	if v := q.Get("printer"); v != "" {
+		req.Printer = v
+	}
+	if v := q.Get("font"); v != "" {
+		req.Font = v
+	}
+	if v := q.Get("boundary"); v != "" {
+		req.Boundary.Mode = layout.BoundaryMode(v)
+	}
+	if v := q.Get("boundary_title"); v != "" {
+		req.Boundary.Title = v
+	}
+	if v := q.Get("boundary_logo_url"); v != "" {
+		req.Boundary.LogoURL = v
+	}
+	if v := q.Get("qr_content"); v != "" {
+		req.QRCode.Content = v
+	}
+
+	bools := map[string]*bool{
+		"double_sided":       &req.DoubleSided,
+		"show_images":        &req.ShowImages,
+		"show_cut_lines":     &req.ShowCutLines,
+		"crop_marks":         &req.CropMarks,
+		"allow_rotation":     &req.AllowRotation,
+		"registration_marks": &req.RegistrationMarks,
+		"boundary_stats":     &req.Boundary.Stats,
+		"qr_code":            &req.QRCode.Enabled,
+	}
+	for key, dst := range bools {
+		if v := q.Get(key); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %q", key, v)
+			}
+			*dst = b
+		}
+	}
+
+	if err := parseFloatParams(q, map[string]*float64{
+		"bleed_mm":       &req.Bleed,
+		"safe_margin_mm": &req.SafeMargin,
+		"gutter_mm":      &req.Gutter,
+		"margin_top":     &req.Margins.Top,
+		"margin_right":   &req.Margins.Right,
+		"margin_bottom":  &req.Margins.Bottom,
+		"margin_left":    &req.Margins.Left,
+		"qr_size_mm":     &req.QRCode.Size,
+	}); err != nil {
+		return err
+	}
+
+	if v := q.Get("set_ids"); v != "" {
+		req.SetIDs = strings.Split(v, ",")
+	}
+	if v := q.Get("group_by"); v != "" {
+		req.GroupBy = strings.Split(v, ",")
+	}
+	if v := q.Get("group_label"); v != "" {
+		req.GroupLabel = v
+	}
+	if v := q.Get("tab_positions"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid tab_positions: %q", v)
+		}
+		req.TabPositions = n
+	}
+
+	if q.Has("page_width") || q.Has("page_height") {
+		page := layout.PageSizes["custom"]
+		if err := parseFloatParams(q, map[string]*float64{
+			"page_width":  &page.Width,
+			"page_height": &page.Height,
+		}); err != nil {
+			return err
+		}
+		req.CustomPageSize = &page
+	}
+	if q.Has("card_width") || q.Has("card_height") || q.Has("tab_height") {
+		card := layout.DefaultCardDimensions
+		if err := parseFloatParams(q, map[string]*float64{
+			"card_width":  &card.Width,
+			"card_height": &card.Height,
+			"tab_height":  &card.TabHeight,
+		}); err != nil {
+			return err
+		}
+		req.CardDimensions = &card
+	}
+	return nil
+}
+
+// parseFloatParams parses the named query parameters that are present
+func parseFloatParams(q url.Values, params map[string]*float64) error {
+	for key, dst := range params {
+		if v := q.Get(key); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %q", key, v)
+			}
+			*dst = f
+		}
+	}
+	return nil
+}
+
+// writeLayoutError maps layout service errors onto HTTP responses
+func writeLayoutError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrPrinterNotFound) || errors.Is(err, services.ErrFontNotFound) || errors.Is(err, services.ErrProfileNotFound) ||
+		errors.Is(err, layout.ErrTooFewCards) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, services.ErrNoCards) || errors.Is(err, services.ErrLayoutNotFound) || errors.Is(err, services.ErrJobNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log.Printf("[API] Failed to build layout: %v", err)
+	http.Error(w, "Failed to build layout", http.StatusInternalServerError)
+}
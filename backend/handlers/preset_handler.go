@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"card-separator/database"
+	"card-separator/preset"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxPresetSize is the largest preset body in bytes accepted
+const maxPresetSize = 1 << 20
+
+type PresetHandler struct {
+	db *database.DB
+}
+
+func NewPresetHandler(db *database.DB) *PresetHandler {
+	return &PresetHandler{db: db}
+}
+
+// ListPresets handles GET /api/presets
+func (h *PresetHandler) ListPresets(w http.ResponseWriter, r *http.Request) {
+	records, err := h.db.GetAllPresets()
+	if err != nil {
+		log.Printf("[API] Failed to fetch presets: %v", err)
+		http.Error(w, "Failed to fetch presets", http.StatusInternalServerError)
+		return
+	}
+
+	presets := make([]*preset.Preset, 0, len(records))
+	for _, rec := range records {
+		p, err := toPreset(rec)
+		if err != nil {
+			log.Printf("[API] Skipping unreadable preset %s: %v", rec.Name, err)
+			continue
+		}
+		presets = append(presets, p)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presets)
+}
+
+// CreatePreset handles POST /api/presets
+// Body: a preset in the export format, so exported files can be imported as
+// is, keeping their createdAt
+func (h *PresetHandler) CreatePreset(w http.ResponseWriter, r *http.Request) {
+	p, ok := readPreset(w, r, "")
+	if !ok {
+		return
+	}
+
+	config, err := json.Marshal(p.Config)
+	if err != nil {
+		http.Error(w, "Failed to encode preset", http.StatusInternalServerError)
+		return
+	}
+	created, err := h.db.InsertPreset(p.Name, config, p.CreatedAt)
+	if err != nil {
+		log.Printf("[API] Failed to save preset %s: %v", p.Name, err)
+		http.Error(w, "Failed to save preset", http.StatusInternalServerError)
+		return
+	}
+	if !created {
+		http.Error(w, fmt.Sprintf("Preset %q already exists", p.Name), http.StatusConflict)
+		return
+	}
+
+	h.writePreset(w, p.Name, http.StatusCreated)
+}
+
+// GetPreset handles GET /api/presets/{name}
+func (h *PresetHandler) GetPreset(w http.ResponseWriter, r *http.Request) {
+	h.writePreset(w, mux.Vars(r)["name"], http.StatusOK)
+}
+
+// SavePreset handles PUT /api/presets/{name}, creating or replacing the
+// preset. The name in the route wins over one in the body.
+func (h *PresetHandler) SavePreset(w http.ResponseWriter, r *http.Request) {
+	p, ok := readPreset(w, r, mux.Vars(r)["name"])
+	if !ok {
+		return
+	}
+
+	config, err := json.Marshal(p.Config)
+	if err != nil {
+		http.Error(w, "Failed to encode preset", http.StatusInternalServerError)
+		return
+	}
+	if err := h.db.UpsertPreset(p.Name, config); err != nil {
+		log.Printf("[API] Failed to save preset %s: %v", p.Name, err)
+		http.Error(w, "Failed to save preset", http.StatusInternalServerError)
+		return
+	}
+
+	h.writePreset(w, p.Name, http.StatusOK)
+}
+
+// DeletePreset handles DELETE /api/presets/{name}
+func (h *PresetHandler) DeletePreset(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	deleted, err := h.db.DeletePreset(name)
+	if err != nil {
+		log.Printf("[API] Failed to delete preset %s: %v", name, err)
+		http.Error(w, "Failed to delete preset", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Preset not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportPreset handles GET /api/presets/{name}/export, returning the preset
+// as a download named like the editor's exports
+func (h *PresetHandler) ExportPreset(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.loadPreset(w, mux.Vars(r)["name"])
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, presetFilename(p.Name)))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(p)
+}
+
+// writePreset writes the stored preset as JSON with the given status
+func (h *PresetHandler) writePreset(w http.ResponseWriter, name string, status int) {
+	p, ok := h.loadPreset(w, name)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// loadPreset fetches a stored preset, writing an error response and
+// returning false if it is missing or unreadable
+func (h *PresetHandler) loadPreset(w http.ResponseWriter, name string) (*preset.Preset, bool) {
+	rec, err := h.db.GetPreset(name)
+	if err != nil {
+		log.Printf("[API] Failed to fetch preset %s: %v", name, err)
+		http.Error(w, "Failed to fetch preset", http.StatusInternalServerError)
+		return nil, false
+	}
+	if rec == nil {
+		http.Error(w, "Preset not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	p, err := toPreset(*rec)
+	if err != nil {
+		log.Printf("[API] Failed to decode preset %s: %v", name, err)
+		http.Error(w, "Failed to decode preset", http.StatusInternalServerError)
+		return nil, false
+	}
+	return p, true
+}
+
+// readPreset decodes and validates a preset from the request body, naming
+// it name when set. It writes a 400 response and returns false if the
+// preset is unusable.
+func readPreset(w http.ResponseWriter, r *http.Request, name string) (*preset.Preset, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPresetSize))
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	p, err := preset.Decode(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if name != "" {
+		p.Name = name
+	}
+	if err := p.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return p, true
+}
+
+// toPreset converts a stored preset into the export format
+func toPreset(rec database.Preset) (*preset.Preset, error) {
+	p := &preset.Preset{
+		Version:   preset.Version,
+		Name:      rec.Name,
+		Config:    preset.DefaultConfig(),
+		CreatedAt: rec.CreatedAt,
+		UpdatedAt: rec.UpdatedAt,
+	}
+	if err := json.Unmarshal(rec.Config, &p.Config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+var filenameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// presetFilename mirrors the editor's export file names: lower case with
+// whitespace turned into dashes
+func presetFilename(name string) string {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if name = filenameUnsafe.ReplaceAllString(name, ""); name == "" {
+		return "preset"
+	}
+	return name
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"card-separator/database"
+	"card-separator/layout"
+	"card-separator/render"
+	"card-separator/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// maxPrinterOffset is the largest back-side offset in mm a profile may store
+const maxPrinterOffset = 20
+
+type PrinterHandler struct {
+	db      *database.DB
+	layouts *services.LayoutService
+}
+
+func NewPrinterHandler(db *database.DB, layouts *services.LayoutService) *PrinterHandler {
+	return &PrinterHandler{
+		db:      db,
+		layouts: layouts,
+	}
+}
+
+// ListPrinters handles GET /api/printers
+func (h *PrinterHandler) ListPrinters(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.db.GetAllPrinterProfiles()
+	if err != nil {
+		log.Printf("[API] Failed to fetch printer profiles: %v", err)
+		http.Error(w, "Failed to fetch printer profiles", http.StatusInternalServerError)
+		return
+	}
+	if profiles == nil {
+		profiles = []database.PrinterProfile{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profiles)
+}
+
+// GetPrinter handles GET /api/printers/{name}
+func (h *PrinterHandler) GetPrinter(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	profile, err := h.db.GetPrinterProfile(name)
+	if err != nil {
+		log.Printf("[API] Failed to fetch printer profile %s: %v", name, err)
+		http.Error(w, "Failed to fetch printer profile", http.StatusInternalServerError)
+		return
+	}
+	if profile == nil {
+		http.Error(w, "Printer profile not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// SavePrinter handles PUT /api/printers/{name}
+// Body: {"offset_x_mm": 0.5, "offset_y_mm": -1.0}
+// With ?add=true the body holds calibration sheet readings, which are added
+// to the stored offset because the sheet is printed with it applied
+func (h *PrinterHandler) SavePrinter(w http.ResponseWriter, r *http.Request) {
+	add, ok := readBool(w, r, "add")
+	if !ok {
+		return
+	}
+	var profile database.PrinterProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	profile.Name = mux.Vars(r)["name"]
+
+	if add {
+		current, err := h.db.GetPrinterProfile(profile.Name)
+		if err != nil {
+			log.Printf("[API] Failed to fetch printer profile %s: %v", profile.Name, err)
+			http.Error(w, "Failed to fetch printer profile", http.StatusInternalServerError)
+			return
+		}
+		// A new profile was calibrated with no offset applied
+		if current != nil {
+			profile.OffsetX += current.OffsetX
+			profile.OffsetY += current.OffsetY
+		}
+	}
+
+	if math.Abs(profile.OffsetX) > maxPrinterOffset || math.Abs(profile.OffsetY) > maxPrinterOffset {
+		http.Error(w, fmt.Sprintf("Offsets must be within ±%dmm", maxPrinterOffset), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.db.UpsertPrinterProfile(&profile); err != nil {
+		log.Printf("[API] Failed to save printer profile %s: %v", profile.Name, err)
+		http.Error(w, "Failed to save printer profile", http.StatusInternalServerError)
+		return
+	}
+
+	h.GetPrinter(w, r)
+}
+
+// DeletePrinter handles DELETE /api/printers/{name}
+func (h *PrinterHandler) DeletePrinter(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	deleted, err := h.db.DeletePrinterProfile(name)
+	if err != nil {
+		log.Printf("[API] Failed to delete printer profile %s: %v", name, err)
+		http.Error(w, "Failed to delete printer profile", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Printer profile not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CalibrationPDF handles GET /api/calibration.pdf?page_size=a4&flip_edge=long&printer=...
+// The named printer's current offset is applied to the back page
+func (h *PrinterHandler) CalibrationPDF(w http.ResponseWriter, r *http.Request) {
+	req := services.NewLayoutRequest()
+	if err := parseLayoutQuery(r.URL.Query(), &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !prepareLayoutRequest(w, h.layouts, &req) {
+		return
+	}
+
+	var offset layout.Offset
+	if req.Printer != "" {
+		var err error
+		if offset, err = h.layouts.PrinterOffset(req.Printer); err != nil {
+			if errors.Is(err, services.ErrPrinterNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			log.Printf("[API] Failed to load printer offset: %v", err)
+			http.Error(w, "Failed to load printer profile", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := render.RenderCalibration(req.Page(), req.FlipEdge, offset, &buf); err != nil {
+		log.Printf("[API] Failed to render calibration sheet: %v", err)
+		http.Error(w, "Failed to render calibration sheet", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="duplex-calibration.pdf"`)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"card-separator/services"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// profileKinds maps the {kind} route variable onto profile kinds
+var profileKinds = map[string]string{
+	"pages": services.ProfilePage,
+	"cards": services.ProfileCard,
+}
+
+type ProfileHandler struct {
+	service *services.ProfileService
+}
+
+func NewProfileHandler(service *services.ProfileService) *ProfileHandler {
+	return &ProfileHandler{service: service}
+}
+
+// ListProfiles handles GET /api/profiles
+// Returns the built-in and custom page sizes and separator profiles
+func (h *ProfileHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	catalogue, err := h.service.ListProfiles()
+	if err != nil {
+		log.Printf("[API] Failed to list profiles: %v", err)
+		http.Error(w, "Failed to list profiles", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(catalogue)
+}
+
+// GetProfile handles GET /api/profiles/{kind}/{name}
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	profile, err := h.service.GetProfile(profileKinds[vars["kind"]], vars["name"])
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// SaveProfile handles PUT /api/profiles/{kind}/{name}
+// Body: {"width_mm": 63, "height_mm": 98, "tab_height_mm": 10}
+func (h *ProfileHandler) SaveProfile(w http.ResponseWriter, r *http.Request) {
+	var profile services.Profile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(r)
+	profile.Name = vars["name"]
+
+	saved, err := h.service.SaveProfile(profileKinds[vars["kind"]], profile)
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// DeleteProfile handles DELETE /api/profiles/{kind}/{name}
+func (h *ProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.DeleteProfile(profileKinds[vars["kind"]], vars["name"]); err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeProfileError maps profile service errors onto HTTP responses
+func writeProfileError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrProfileNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrBuiltinProfile):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidProfile):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[API] Profile error: %v", err)
+		http.Error(w, "Failed to process profile", http.StatusInternalServerError)
+	}
+}
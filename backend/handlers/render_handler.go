@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"card-separator/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// renderHeartbeat is how often an idle progress stream sends a comment to
+// keep proxies from closing it
+const renderHeartbeat = 15 * time.Second
+
+type RenderHandler struct {
+	queue   *services.RenderQueue
+	layouts *services.LayoutService
+}
+
+func NewRenderHandler(queue *services.RenderQueue, layouts *services.LayoutService) *RenderHandler {
+	return &RenderHandler{queue: queue, layouts: layouts}
+}
+
+// QueueRender handles POST /api/renders
+// Body: {"job_id": "..."} or a layout request; returns 202 with the task
+func (h *RenderHandler) QueueRender(w http.ResponseWriter, r *http.Request) {
+	req := services.RenderRequest{LayoutRequest: services.NewLayoutRequest()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.JobID == "" {
+		if req.SetID == "" && len(req.SetIDs) == 0 && len(req.Cards) == 0 {
+			http.Error(w, "One of 'job_id', 'set_id', 'set_ids' or 'cards' is required", http.StatusBadRequest)
+			return
+		}
+		if !prepareLayoutRequest(w, h.layouts, &req.LayoutRequest) {
+			return
+		}
+	}
+
+	task, err := h.queue.Enqueue(req)
+	if err != nil {
+		writeRenderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/renders/"+task.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(task)
+}
+
+// GetRender handles GET /api/renders/{id}
+func (h *RenderHandler) GetRender(w http.ResponseWriter, r *http.Request) {
+	task, err := h.queue.Status(mux.Vars(r)["id"])
+	if err != nil {
+		writeRenderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// StreamRender handles GET /api/renders/{id}/events, sending the task as a
+// Server-Sent "status" event on every change until it is done or failed
+func (h *RenderHandler) StreamRender(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	task, changed, err := h.queue.Watch(id)
+	if err != nil {
+		writeRenderError(w, err)
+		return
+	}
+
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(renderHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		data, _ := json.Marshal(task)
+		fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+		if err := rc.Flush(); err != nil || task.State.Finished() {
+			return
+		}
+
+	wait:
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			case <-changed:
+				break wait
+			}
+		}
+
+		if task, changed, err = h.queue.Watch(id); err != nil {
+			return
+		}
+	}
+}
+
+// DownloadRender handles GET /api/renders/{id}/file
+func (h *RenderHandler) DownloadRender(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	data, err := h.queue.File(r.Context(), id)
+	if err != nil {
+		writeRenderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="render-%s-separators.pdf"`, id))
+	w.Write(data)
+}
+
+// writeRenderError maps render queue errors onto HTTP responses
+func writeRenderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrRenderNotFound), errors.Is(err, services.ErrJobNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrRenderNotReady):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrQueueFull):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		log.Printf("[API] Render queue error: %v", err)
+		http.Error(w, "Failed to process render", http.StatusInternalServerError)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"card-separator/database"
 	"card-separator/services"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -34,20 +36,41 @@ func (h *SetHandler) ListSets(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(sets)
 }
 
-// SyncSets handles POST /api/sets/sync
+// SyncSets handles POST /api/sets/sync?force=true. Without force the sync
+// is conditional on the last one and unchanged sets are skipped.
 func (h *SetHandler) SyncSets(w http.ResponseWriter, r *http.Request) {
-	count, err := h.syncService.SyncAllSets()
+	force, ok := readBool(w, r, "force")
+	if !ok {
+		return
+	}
+
+	result, err := h.syncService.SyncAllSets(services.SyncTriggerAPI, force)
 	if err != nil {
 		log.Printf("[API] Failed to sync sets: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	response := map[string]interface{}{
-		"synced_sets": count,
-		"timestamp":   time.Now().Format(time.RFC3339),
-	}
+	response := struct {
+		SyncedSets int    `json:"synced_sets"`
+		Timestamp  string `json:"timestamp"`
+		*services.SyncResult
+	}{result.Total(), result.SyncedAt.Format(time.RFC3339), result}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// readBool reads a boolean query parameter of sync requests, false if unset
+func readBool(w http.ResponseWriter, r *http.Request, name string) (bool, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, true
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s: %q", name, v), http.StatusBadRequest)
+		return false, false
+	}
+	return b, true
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"card-separator/database"
+	"card-separator/services"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Page sizes of GET /api/sync/runs
+const (
+	defaultSyncRuns = 50
+	maxSyncRuns     = 500
+)
+
+type SyncHandler struct {
+	db *database.DB
+}
+
+func NewSyncHandler(db *database.DB) *SyncHandler {
+	return &SyncHandler{db: db}
+}
+
+// ListRuns handles GET /api/sync/runs?trigger=&scope=&status=&since=&limit=&offset=
+// trigger is startup, ticker or api, scope is "sets", "cards" or
+// "cards/{set_id}", status is ok or failed and since an RFC 3339 time.
+// Runs are listed newest first.
+func (h *SyncHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := database.SyncRunFilter{
+		Trigger: q.Get("trigger"),
+		Scope:   q.Get("scope"),
+		Status:  q.Get("status"),
+		Limit:   defaultSyncRuns,
+	}
+
+	if filter.Trigger != "" && !slices.Contains(services.SyncTriggers, filter.Trigger) {
+		http.Error(w, fmt.Sprintf("invalid trigger: %q", filter.Trigger), http.StatusBadRequest)
+		return
+	}
+	if filter.Status != "" && filter.Status != database.SyncRunOK && filter.Status != database.SyncRunFailed {
+		http.Error(w, fmt.Sprintf("invalid status: %q (want %q or %q)", filter.Status, database.SyncRunOK, database.SyncRunFailed), http.StatusBadRequest)
+		return
+	}
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %q", v), http.StatusBadRequest)
+			return
+		}
+		filter.Since = since
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxSyncRuns {
+			http.Error(w, fmt.Sprintf("invalid limit: %q (1-%d)", v, maxSyncRuns), http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			http.Error(w, fmt.Sprintf("invalid offset: %q", v), http.StatusBadRequest)
+			return
+		}
+		filter.Offset = offset
+	}
+
+	runs, err := h.db.GetSyncRuns(filter)
+	if err != nil {
+		log.Printf("[API] Failed to fetch sync runs: %v", err)
+		http.Error(w, "Failed to fetch sync runs", http.StatusInternalServerError)
+		return
+	}
+	if runs == nil {
+		runs = []database.SyncRun{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"card-separator/database"
+	"card-separator/tabtemplate"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// maxPreviewCards caps how many cards a template preview renders
+const maxPreviewCards = 100
+
+type TemplateHandler struct {
+	db *database.DB
+}
+
+func NewTemplateHandler(db *database.DB) *TemplateHandler {
+	return &TemplateHandler{db: db}
+}
+
+// previewRequest selects a template and the cards to render it against
+type previewRequest struct {
+	Template   string          `json:"template"`
+	SetID      string          `json:"set_id,omitempty"`
+	CardSetIDs []string        `json:"card_set_ids,omitempty"`
+	Cards      []database.Card `json:"cards,omitempty"`
+	Limit      int             `json:"limit,omitempty"`
+}
+
+// previewResult is one card's rendered tab text
+type previewResult struct {
+	CardSetID string `json:"card_set_id"`
+	CardName  string `json:"card_name"`
+	Text      string `json:"text"`
+}
+
+// PreviewTemplate handles POST /api/templates/preview
+func (h *TemplateHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	var req previewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Limit <= 0 || req.Limit > maxPreviewCards {
+		req.Limit = 10
+	}
+
+	tmpl, err := tabtemplate.Parse(req.Template)
+	if err != nil {
+		var tmplErr *tabtemplate.Error
+		if errors.As(err, &tmplErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tmplErr)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cards := req.Cards
+	switch {
+	case len(cards) > 0:
+	case len(req.CardSetIDs) > 0:
+		cards, err = h.db.GetCardsByIDs(req.CardSetIDs)
+	case req.SetID != "":
+		cards, err = h.db.GetCardsBySet(req.SetID)
+	default:
+		http.Error(w, "One of 'set_id', 'card_set_ids' or 'cards' is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("[API] Failed to fetch cards for template preview: %v", err)
+		http.Error(w, "Failed to fetch cards", http.StatusInternalServerError)
+		return
+	}
+	if len(cards) > req.Limit {
+		cards = cards[:req.Limit]
+	}
+
+	results := make([]previewResult, len(cards))
+	for i, card := range cards {
+		results[i] = previewResult{
+			CardSetID: card.CardSetID,
+			CardName:  card.CardName,
+			Text:      tmpl.Execute(card),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"template": tmpl.String(),
+		"fields":   tabtemplate.FieldNames(),
+		"results":  results,
+	})
+}
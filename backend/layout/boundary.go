@@ -0,0 +1,140 @@
+package layout
+
+import (
+	"card-separator/database"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// BoundaryMode selects what the outer faces of a collection show: the back
+// of the first separator and the front of the last
+type BoundaryMode string
+
+const (
+	BoundaryBlank   BoundaryMode = "blank"   // Empty "Collection Boundary" faces, like the frontend
+	BoundaryContent BoundaryMode = "content" // Title, logo and stats from the Boundary config
+	BoundaryNone    BoundaryMode = "none"    // No boundary separators at all
+)
+
+// ErrTooFewCards is returned when dropping the boundaries would leave no
+// separators, for fewer than two cards
+var ErrTooFewCards = errors.New("boundary mode none needs at least two cards")
+
+// MaxBoundaryTitle is the longest boundary title in characters
+const MaxBoundaryTitle = 100
+
+// Boundary configures the collection boundary separators
+type Boundary struct {
+	Mode    BoundaryMode `json:"mode,omitempty"`     // BoundaryBlank when empty
+	Title   string       `json:"title,omitempty"`    // Tab label, the collection's set name when empty
+	LogoURL string       `json:"logo_url,omitempty"` // Drawn in the art area, e.g. a set logo
+	Stats   bool         `json:"stats,omitempty"`    // Card count and colours under the art
+}
+
+// Validate checks the boundary mode and content
+func (b Boundary) Validate() error {
+	switch b.Mode {
+	case "", BoundaryBlank, BoundaryNone:
+		return nil
+	case BoundaryContent:
+	default:
+		return fmt.Errorf("invalid boundary mode: %q", b.Mode)
+	}
+
+	if utf8.RuneCountInString(b.Title) > MaxBoundaryTitle {
+		return fmt.Errorf("boundary title must be at most %d characters", MaxBoundaryTitle)
+	}
+	if b.LogoURL != "" {
+		u, err := url.Parse(b.LogoURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid boundary logo_url: %q", b.LogoURL)
+		}
+	}
+	return nil
+}
+
+// Card returns the placeholder printed on the boundary faces of cards. In
+// content mode it carries the title as its name and the logo as its art.
+func (b Boundary) Card(cards []database.Card) database.Card {
+	card := CreateBlankCard()
+	if b.Mode != BoundaryContent {
+		return card
+	}
+	card.CardName = b.Title
+	if card.CardName == "" {
+		card.CardName = collectionTitle(cards)
+	}
+	card.CardImageURL = b.LogoURL
+	return card
+}
+
+// BoundaryStats summarises cards for the boundary faces: the card count and
+// how many cards have each colour, most common first. Multicolour cards
+// count towards each of their colours.
+func BoundaryStats(cards []database.Card) []string {
+	count := 0
+	colors := make(map[string]int)
+	for _, card := range cards {
+		if IsSetHeader(card) || IsBlank(card) {
+			continue
+		}
+		count++
+		for _, c := range strings.Fields(strings.ReplaceAll(card.CardColor, "/", " ")) {
+			colors[c]++
+		}
+	}
+
+	stats := []string{fmt.Sprintf("%d cards", count)}
+	if count == 1 {
+		stats[0] = "1 card"
+	}
+	if len(colors) == 0 {
+		return stats
+	}
+
+	names := make([]string, 0, len(colors))
+	for c := range colors {
+		names = append(names, c)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if colors[names[i]] != colors[names[j]] {
+			return colors[names[i]] > colors[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, c := range names {
+		parts[i] = fmt.Sprintf("%s %d", c, colors[c])
+	}
+	return append(stats, strings.Join(parts, " · "))
+}
+
+// collectionTitle names a card list by its set, or by how many sets it
+// spans
+func collectionTitle(cards []database.Card) string {
+	var sets []string
+	seen := make(map[string]bool)
+	for _, card := range cards {
+		if IsSetHeader(card) || card.SetID == "" || seen[card.SetID] {
+			continue
+		}
+		seen[card.SetID] = true
+		name := card.SetName
+		if name == "" {
+			name = card.SetID
+		}
+		sets = append(sets, name)
+	}
+
+	switch len(sets) {
+	case 0:
+		return "Collection"
+	case 1:
+		return sets[0]
+	}
+	return fmt.Sprintf("Collection · %d sets", len(sets))
+}
@@ -0,0 +1,288 @@
+package layout
+
+import (
+	"card-separator/tabtemplate"
+	"fmt"
+	"math"
+)
+
+// FlipEdge selects how back pages are mirrored for double-sided printing
+type FlipEdge string
+
+const (
+	FlipLong  FlipEdge = "long"  // Book flip: mirror each row
+	FlipShort FlipEdge = "short" // Calendar flip: reverse the whole page
+)
+
+// PageDimensions is a page size in millimetres
+type PageDimensions struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// CardDimensions is a separator size in millimetres, tab included
+type CardDimensions struct {
+	Width     float64 `json:"width"`
+	Height    float64 `json:"height"`
+	TabHeight float64 `json:"tab_height"`
+}
+
+// DefaultCardDimensions matches DEFAULT_CARD_DIMENSIONS in web/src/lib/config.ts
+var DefaultCardDimensions = CardDimensions{
+	Width:     65,
+	Height:    95,
+	TabHeight: 10,
+}
+
+// PageSizes are the built-in page sizes: PAGE_SIZES in web/src/lib/config.ts
+// plus A3, A5 and tabloid
+var PageSizes = map[string]PageDimensions{
+	"a3":      {Width: 297, Height: 420},
+	"a4":      {Width: 210, Height: 297},
+	"a5":      {Width: 148, Height: 210},
+	"letter":  {Width: 216, Height: 279},
+	"legal":   {Width: 216, Height: 356},
+	"tabloid": {Width: 279, Height: 432},
+	"custom":  {Width: 210, Height: 297},
+}
+
+// profileTabHeight is the tab added above the item in separator profiles
+const profileTabHeight = 10
+
+// CardProfiles are the built-in separator sizes. Each is as wide and, below
+// its tab, as tall as the card or sleeve it divides.
+var CardProfiles = map[string]CardDimensions{
+	"default":               DefaultCardDimensions,
+	"standard":              {Width: 63, Height: 88 + profileTabHeight, TabHeight: profileTabHeight},
+	"japanese":              {Width: 59, Height: 86 + profileTabHeight, TabHeight: profileTabHeight},
+	"standard-inner-sleeve": {Width: 64, Height: 89 + profileTabHeight, TabHeight: profileTabHeight},
+	"standard-outer-sleeve": {Width: 66, Height: 91 + profileTabHeight, TabHeight: profileTabHeight},
+	"japanese-inner-sleeve": {Width: 60, Height: 87 + profileTabHeight, TabHeight: profileTabHeight},
+	"japanese-outer-sleeve": {Width: 62, Height: 89 + profileTabHeight, TabHeight: profileTabHeight},
+}
+
+// MaxBleed is the largest bleed in mm a config may request
+const MaxBleed = 10
+
+// CropMarkSpace is the minimum room in mm left around each trim box for
+// crop marks when there is less bleed than this
+const CropMarkSpace = 3
+
+// MaxTabPositions is the most staggered tab positions a config may request
+const MaxTabPositions = 10
+
+// ImageQualities are the image sizes served by the image service
+var ImageQualities = []string{"thumbnail", "medium", "full", "original"}
+
+// PrintConfig holds the print settings shared with the frontend PrintConfig
+type PrintConfig struct {
+	DoubleSided       bool            `json:"double_sided"`
+	FlipEdge          FlipEdge        `json:"flip_edge"`
+	ShowImages        bool            `json:"show_images"`
+	ImageQuality      string          `json:"image_quality"`
+	ShowCutLines      bool            `json:"show_cut_lines"`
+	PageSize          string          `json:"page_size"`
+	CustomPageSize    *PageDimensions `json:"custom_page_size,omitempty"`
+	CardDimensions    *CardDimensions `json:"card_dimensions,omitempty"`
+	CardProfile       string          `json:"card_profile,omitempty"` // Named separator size, used when card_dimensions is unset
+	Printer           string          `json:"printer,omitempty"`      // Printer profile whose back offset is applied
+	Bleed             float64         `json:"bleed_mm"`               // Art extends this far past the trim line
+	SafeMargin        float64         `json:"safe_margin_mm"`         // Text and art stay this far inside the trim line
+	CropMarks         bool            `json:"crop_marks"`
+	Margins           Margins         `json:"margins"`                // Unprintable page edges kept clear
+	Gutter            float64         `json:"gutter_mm"`              // Extra space between neighbouring separators
+	AllowRotation     bool            `json:"allow_rotation"`         // Turn separators 90° when more fit on a page
+	TabPositions      int             `json:"tab_positions"`          // Staggered tab cuts cycled across separators, 0 or 1 for full width
+	TabTemplate       string          `json:"tab_template,omitempty"` // Tab label template, see package tabtemplate
+	Font              string          `json:"font,omitempty"`         // Tab label font from the font registry, fonts.Default when empty
+	RegistrationMarks bool            `json:"registration_marks"`     // Print-and-cut marks on front pages, see RegistrationMarks
+	Boundary          Boundary        `json:"boundary"`               // Outer faces of the collection
+	QRCode            QRCode          `json:"qr_code"`                // Code linking each card's faces to its data
+}
+
+// DefaultPrintConfig returns the defaults used by the frontend (DEFAULT_CONFIG)
+func DefaultPrintConfig() PrintConfig {
+	return PrintConfig{
+		DoubleSided:  false,
+		FlipEdge:     FlipLong,
+		ShowImages:   true,
+		ImageQuality: "medium",
+		ShowCutLines: false,
+		PageSize:     "a4",
+	}
+}
+
+// Page returns the page dimensions selected by the config
+func (c PrintConfig) Page() PageDimensions {
+	if c.PageSize == "custom" && c.CustomPageSize != nil {
+		return *c.CustomPageSize
+	}
+	return PageSizes[c.PageSize]
+}
+
+// Card returns the separator dimensions selected by the config
+func (c PrintConfig) Card() CardDimensions {
+	if c.CardDimensions != nil {
+		return *c.CardDimensions
+	}
+	if card, ok := CardProfiles[c.CardProfile]; ok {
+		return card
+	}
+	return DefaultCardDimensions
+}
+
+// Validate checks the config can produce a layout
+func (c PrintConfig) Validate() error {
+	if c.FlipEdge != FlipLong && c.FlipEdge != FlipShort {
+		return fmt.Errorf("invalid flip_edge: %q", c.FlipEdge)
+	}
+	if !validImageQuality(c.ImageQuality) {
+		return fmt.Errorf("invalid image_quality: %q", c.ImageQuality)
+	}
+	if _, ok := PageSizes[c.PageSize]; !ok {
+		return fmt.Errorf("invalid page_size: %q", c.PageSize)
+	}
+
+	page := c.Page()
+	if page.Width <= 0 || page.Height <= 0 {
+		return fmt.Errorf("page dimensions must be positive")
+	}
+
+	if _, ok := CardProfiles[c.CardProfile]; c.CardProfile != "" && c.CardDimensions == nil && !ok {
+		return fmt.Errorf("invalid card_profile: %q", c.CardProfile)
+	}
+	card := c.Card()
+	if card.Width <= 0 || card.Height <= 0 {
+		return fmt.Errorf("card dimensions must be positive")
+	}
+	if card.TabHeight < 0 || card.TabHeight >= card.Height {
+		return fmt.Errorf("tab_height must be between 0 and the card height")
+	}
+
+	if c.Bleed < 0 || c.Bleed > MaxBleed {
+		return fmt.Errorf("bleed_mm must be between 0 and %d", MaxBleed)
+	}
+	if c.SafeMargin < 0 || 2*c.SafeMargin >= card.Width || 2*c.SafeMargin >= card.Height-card.TabHeight {
+		return fmt.Errorf("safe_margin_mm must be non-negative and leave room inside the card")
+	}
+
+	if c.TabPositions < 0 || c.TabPositions > MaxTabPositions {
+		return fmt.Errorf("tab_positions must be between 0 and %d", MaxTabPositions)
+	}
+
+	if _, err := c.Template(); err != nil {
+		return fmt.Errorf("invalid tab_template: %w", err)
+	}
+	if err := c.Boundary.Validate(); err != nil {
+		return err
+	}
+	if err := c.QRCode.Validate(); err != nil {
+		return err
+	}
+
+	m := c.Margins
+	if m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0 {
+		return fmt.Errorf("margins must be non-negative")
+	}
+	if c.Gutter < 0 {
+		return fmt.Errorf("gutter_mm must be non-negative")
+	}
+
+	if Impose(c.ImpositionParams()).PerPage == 0 {
+		return fmt.Errorf("card (%gx%gmm) does not fit on page (%gx%gmm) with the requested bleed, margins, gutter and registration marks",
+			card.Width, card.Height, page.Width, page.Height)
+	}
+	return nil
+}
+
+// Spacing returns the space kept around each trim box: the bleed, widened to
+// leave room for crop marks when they are enabled
+func (c PrintConfig) Spacing() float64 {
+	if c.CropMarks && c.Bleed < CropMarkSpace {
+		return CropMarkSpace
+	}
+	return c.Bleed
+}
+
+// TabCount returns how many tab positions separators cycle through
+func (c PrintConfig) TabCount() int {
+	if c.TabPositions < 1 {
+		return 1
+	}
+	return c.TabPositions
+}
+
+// Template parses the tab template, returning nil when none is set
+func (c PrintConfig) Template() (*tabtemplate.Template, error) {
+	if c.TabTemplate == "" {
+		return nil, nil
+	}
+	return tabtemplate.Parse(c.TabTemplate)
+}
+
+// ImpositionParams returns what the config needs to fit on each page
+func (c PrintConfig) ImpositionParams() ImpositionParams {
+	return ImpositionParams{
+		Page:          c.Page(),
+		Card:          c.Card(),
+		Spacing:       c.Spacing(),
+		Margins:       c.margins(),
+		Gutter:        c.Gutter,
+		AllowRotation: c.AllowRotation,
+	}
+}
+
+// margins returns the configured margins, widened to keep the grid clear of
+// registration marks when they are enabled
+func (c PrintConfig) margins() Margins {
+	m := c.Margins
+	if c.RegistrationMarks {
+		m.Top = math.Max(m.Top, RegistrationSpace)
+		m.Right = math.Max(m.Right, RegistrationSpace)
+		m.Bottom = math.Max(m.Bottom, RegistrationSpace)
+		m.Left = math.Max(m.Left, RegistrationSpace)
+	}
+	return m
+}
+
+func validImageQuality(quality string) bool {
+	for _, q := range ImageQualities {
+		if q == quality {
+			return true
+		}
+	}
+	return false
+}
+
+// Offset is a shift in millimetres
+type Offset struct {
+	X float64 `json:"x_mm"`
+	Y float64 `json:"y_mm"`
+}
+
+// Box is a rectangle in millimetres from the page's top-left corner
+type Box struct {
+	X      float64 `json:"x_mm"`
+	Y      float64 `json:"y_mm"`
+	Width  float64 `json:"width_mm"`
+	Height float64 `json:"height_mm"`
+}
+
+// Inset returns the box shrunk by d on every side (grown for negative d)
+func (b Box) Inset(d float64) Box {
+	return Box{X: b.X + d, Y: b.Y + d, Width: b.Width - 2*d, Height: b.Height - 2*d}
+}
+
+// Union returns the smallest box containing both b and o
+func (b Box) Union(o Box) Box {
+	x1, y1 := math.Min(b.X, o.X), math.Min(b.Y, o.Y)
+	x2, y2 := math.Max(b.X+b.Width, o.X+o.Width), math.Max(b.Y+b.Height, o.Y+o.Height)
+	return Box{X: x1, Y: y1, Width: x2 - x1, Height: y2 - y1}
+}
+
+// Grid describes how many separators fit on a page
+type Grid struct {
+	CardsPerRow  int `json:"cards_per_row"`
+	RowsPerPage  int `json:"rows_per_page"`
+	CardsPerPage int `json:"cards_per_page"`
+}
@@ -0,0 +1,23 @@
+package layout
+
+import "fmt"
+
+// ManualDuplex splits a double-sided layout into two print runs for
+// printers without duplexing: every front page, then every back page, both
+// in sheet order. A face-down output tray leaves the last sheet on top;
+// turning the stack over puts sheet 1 back on top, blank side down, ready
+// for the backs. Back pages keep their flip transform and the printer's
+// offset.
+func (l *Layout) ManualDuplex() (fronts, backs []Page, err error) {
+	if !l.Config.DoubleSided {
+		return nil, nil, fmt.Errorf("manual duplex needs a double-sided layout")
+	}
+	for _, page := range l.Pages {
+		if page.Type == PageFront {
+			fronts = append(fronts, page)
+		} else {
+			backs = append(backs, page)
+		}
+	}
+	return fronts, backs, nil
+}
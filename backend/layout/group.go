@@ -0,0 +1,145 @@
+package layout
+
+import (
+	"card-separator/database"
+	"card-separator/tabtemplate"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultGroupLabels name numeric fields in labels built without a template
+var defaultGroupLabels = map[string]string{
+	"card_cost":  "Cost {card_cost}",
+	"card_power": "Power {card_power}",
+}
+
+// GroupPrefix starts the CardSetID of group entries, followed by the group's
+// field values joined by GroupKeySeparator
+const GroupPrefix = "group:"
+
+// GroupKeySeparator joins a group's field values in its CardSetID
+const GroupKeySeparator = "|"
+
+// IsGroup reports whether a card is a group entry made by GroupCards
+func IsGroup(card database.Card) bool {
+	return strings.HasPrefix(card.CardSetID, GroupPrefix)
+}
+
+// ValidateGrouping checks the group fields and label template are known
+func ValidateGrouping(fields []string, label string) error {
+	for _, f := range fields {
+		if _, ok := tabtemplate.Lookup(f); !ok {
+			return fmt.Errorf("invalid group_by field: %q", f)
+		}
+	}
+	if _, err := tabtemplate.Parse(label); err != nil {
+		return fmt.Errorf("invalid group_label: %w", err)
+	}
+	return nil
+}
+
+// GroupCards collapses cards into one entry per distinct combination of the
+// given fields, so the usual pairing rules give one separator per group.
+// Groups are sorted by their values, numeric fields numerically. Each entry
+// is named by the label template (see package tabtemplate); an empty
+// template joins the values with " · ". The entry keeps the first card's art
+// and set. Set headers stay in place and the cards of each set are grouped
+// separately.
+func GroupCards(cards []database.Card, fields []string, label string) ([]database.Card, error) {
+	if err := ValidateGrouping(fields, label); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return cards, nil
+	}
+	if label == "" {
+		label = defaultGroupLabel(fields)
+	}
+	tmpl, err := tabtemplate.Parse(label)
+	if err != nil {
+		return nil, err
+	}
+
+	var grouped []database.Card
+	start := 0
+	for i := 0; i <= len(cards); i++ {
+		if i < len(cards) && !IsSetHeader(cards[i]) {
+			continue
+		}
+		grouped = append(grouped, groupRun(cards[start:i], fields, tmpl)...)
+		if i < len(cards) {
+			grouped = append(grouped, cards[i])
+		}
+		start = i + 1
+	}
+	return grouped, nil
+}
+
+// groupRun groups a run of cards that contains no set headers
+func groupRun(cards []database.Card, fields []string, label *tabtemplate.Template) []database.Card {
+	sorted := make([]database.Card, len(cards))
+	copy(sorted, cards)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareGroups(sorted[i], sorted[j], fields) < 0
+	})
+
+	var groups []database.Card
+	for i, card := range sorted {
+		if i > 0 && compareGroups(sorted[i-1], card, fields) == 0 {
+			continue
+		}
+		values := make([]string, len(fields))
+		for k, f := range fields {
+			values[k] = field(f)(card)
+		}
+		groups = append(groups, database.Card{
+			CardSetID:    GroupPrefix + strings.Join(values, GroupKeySeparator),
+			CardName:     label.Execute(card),
+			SetID:        card.SetID,
+			SetName:      card.SetName,
+			CardImageURL: card.CardImageURL,
+		})
+	}
+	return groups
+}
+
+// defaultGroupLabel builds a template naming every group field
+func defaultGroupLabel(fields []string) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		if l, ok := defaultGroupLabels[f]; ok {
+			parts[i] = l
+		} else {
+			parts[i] = "{" + f + "}"
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// compareGroups orders two cards by the group fields
+func compareGroups(a, b database.Card, fields []string) int {
+	for _, f := range fields {
+		switch f {
+		case "card_cost", "cost":
+			if a.CardCost != b.CardCost {
+				return a.CardCost - b.CardCost
+			}
+		case "card_power":
+			if a.CardPower != b.CardPower {
+				return a.CardPower - b.CardPower
+			}
+		default:
+			if c := strings.Compare(field(f)(a), field(f)(b)); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// field returns the getter for a validated field name
+func field(name string) func(database.Card) string {
+	get, _ := tabtemplate.Lookup(name)
+	return get
+}
@@ -0,0 +1,90 @@
+package layout
+
+import "math"
+
+// Margins are the unprintable page edges in millimetres
+type Margins struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+// ImpositionParams describes what has to fit on a page
+type ImpositionParams struct {
+	Page          PageDimensions `json:"page"`
+	Card          CardDimensions `json:"card"`
+	Spacing       float64        `json:"spacing_mm"` // Kept around every trim box (bleed or crop mark room)
+	Margins       Margins        `json:"margins"`
+	Gutter        float64        `json:"gutter_mm"` // Between neighbouring separators
+	AllowRotation bool           `json:"allow_rotation"`
+}
+
+// Imposition is the densest grid of separators found for a page
+type Imposition struct {
+	Columns      int     `json:"columns"`
+	Rows         int     `json:"rows"`
+	PerPage      int     `json:"per_page"`
+	Rotated      bool    `json:"rotated"`     // Separators turned 90° clockwise
+	ItemWidth    float64 `json:"item_width"`  // Footprint of one separator on the page, spacing included
+	ItemHeight   float64 `json:"item_height"` //
+	PitchX       float64 `json:"pitch_x"`     // Distance between neighbouring columns
+	PitchY       float64 `json:"pitch_y"`     // Distance between neighbouring rows
+	OriginX      float64 `json:"origin_x"`    // Top-left corner of the grid
+	OriginY      float64 `json:"origin_y"`
+	WastePercent float64 `json:"waste_percent"` // Share of the page outside every trim box
+}
+
+// Impose finds the grid that fits the most separators on the page, trying
+// both orientations when rotation is allowed. Upright wins ties. The grid
+// starts at the top-left margin, like the browser print view.
+func Impose(p ImpositionParams) Imposition {
+	best := fitGrid(p, false)
+	if p.AllowRotation {
+		if rotated := fitGrid(p, true); rotated.PerPage > best.PerPage {
+			best = rotated
+		}
+	}
+	return best
+}
+
+// fitGrid fits as many separators as possible in one orientation
+func fitGrid(p ImpositionParams, rotated bool) Imposition {
+	w, h := p.Card.Width, p.Card.Height
+	if rotated {
+		w, h = h, w
+	}
+	itemW, itemH := w+2*p.Spacing, h+2*p.Spacing
+
+	availW := p.Page.Width - p.Margins.Left - p.Margins.Right
+	availH := p.Page.Height - p.Margins.Top - p.Margins.Bottom
+
+	imp := Imposition{
+		Columns:    fitCount(availW, itemW, p.Gutter),
+		Rows:       fitCount(availH, itemH, p.Gutter),
+		Rotated:    rotated,
+		ItemWidth:  itemW,
+		ItemHeight: itemH,
+		PitchX:     itemW + p.Gutter,
+		PitchY:     itemH + p.Gutter,
+		OriginX:    p.Margins.Left,
+		OriginY:    p.Margins.Top,
+	}
+	imp.PerPage = imp.Columns * imp.Rows
+
+	pageArea := p.Page.Width * p.Page.Height
+	if pageArea > 0 {
+		used := float64(imp.PerPage) * p.Card.Width * p.Card.Height
+		imp.WastePercent = math.Round((1-used/pageArea)*10000) / 100
+	}
+	return imp
+}
+
+// fitCount returns how many items of size fit in avail with gap between them
+func fitCount(avail, size, gap float64) int {
+	if avail < size || size <= 0 {
+		return 0
+	}
+	// Small epsilon so exact fits are not lost to floating point error
+	return int((avail+gap)/(size+gap) + 1e-9)
+}
@@ -0,0 +1,37 @@
+package layout
+
+import (
+	"card-separator/database"
+	"card-separator/tabtemplate"
+	"regexp"
+)
+
+var (
+	cardIDPrefix = regexp.MustCompile(`^[A-Z]+[0-9]+-[0-9]+\s+`)
+	setIDPrefix  = regexp.MustCompile(`^[A-Z]+-[0-9]+\s+`)
+)
+
+// DefaultTabLabel returns the label drawn on a separator tab when no
+// template is set. Like the frontend it strips "OP01-001 " and "OP-01 "
+// prefixes from the card name.
+func DefaultTabLabel(card database.Card) string {
+	name := cardIDPrefix.ReplaceAllString(card.CardName, "")
+	return setIDPrefix.ReplaceAllString(name, "")
+}
+
+// TabLabel returns the tab label for a separator face. The template only
+// applies to real cards: boundaries, set headers and groups carry their own
+// label, as does a card whose template renders empty. Boundary titles are
+// used as given.
+func TabLabel(card database.Card, tmpl *tabtemplate.Template) string {
+	if IsBlank(card) {
+		return card.CardName
+	}
+	if tmpl == nil || IsSetHeader(card) || IsGroup(card) {
+		return DefaultTabLabel(card)
+	}
+	if label := tmpl.Execute(card); label != "" {
+		return label
+	}
+	return DefaultTabLabel(card)
+}
@@ -0,0 +1,238 @@
+package layout
+
+import (
+	"card-separator/database"
+	"card-separator/tabtemplate"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Placement positions one separator face on a print page
+type Placement struct {
+	Slot      int           `json:"slot"` // 0-indexed slot on the page grid
+	Row       int           `json:"row"`
+	Column    int           `json:"column"`
+	X         float64       `json:"x_mm"` // Offset from the page's top-left corner
+	Y         float64       `json:"y_mm"`
+	Width     float64       `json:"width_mm"`
+	Height    float64       `json:"height_mm"`
+	Rotation  int           `json:"rotation"`  // Degrees clockwise the face is turned: 0, 90 or 270
+	Tab       int           `json:"tab"`       // Tab position from the left of the upright face
+	BleedBox  Box           `json:"bleed_box"` // Trim box grown by the bleed
+	SafeBox   Box           `json:"safe_box"`  // Trim box shrunk by the safe margin
+	Separator int           `json:"separator"` // Position of the SeparatorPair
+	Blank     bool          `json:"blank"`
+	Header    bool          `json:"header"`            // Set header in a multi-set collection
+	Label     string        `json:"label"`             // Text drawn on the tab
+	Details   []string      `json:"details,omitempty"` // Summary lines drawn under the art of boundary faces
+	QRCode    string        `json:"qr_code,omitempty"` // Content of the QR code drawn on the face
+	Card      database.Card `json:"card"`
+}
+
+// Page is one side of a printed sheet with its placements
+type Page struct {
+	Number     int         `json:"number"` // 1-indexed print order
+	Sheet      int         `json:"sheet"`  // 1-indexed physical sheet
+	Type       PageType    `json:"type"`
+	TrimBox    Box         `json:"trim_box"`  // Bounds of every trim box on the page
+	BleedBox   Box         `json:"bleed_box"` // Bounds of every bleed box on the page
+	Placements []Placement `json:"placements"`
+}
+
+// Layout is the complete page-by-page print layout for a card list
+type Layout struct {
+	ID             string         `json:"id,omitempty"` // Set once the layout is saved
+	Config         PrintConfig    `json:"config"`
+	Page           PageDimensions `json:"page"`
+	Card           CardDimensions `json:"card"`
+	Grid           Grid           `json:"grid"`
+	Imposition     Imposition     `json:"imposition"`
+	CardCount      int            `json:"card_count"`
+	SeparatorCount int            `json:"separator_count"`
+	SheetCount     int            `json:"sheet_count"`
+	BackOffset     Offset         `json:"back_offset"`                  // From the printer profile, applied to back pages
+	Registration   []Box          `json:"registration_marks,omitempty"` // Print-and-cut marks drawn on front pages
+	Pages          []Page         `json:"pages"`
+	CreatedAt      time.Time      `json:"-"` // Fixes the document date when set, so saved jobs render identically
+	FontVersion    string         `json:"-"` // SHA-256 of the uploaded tab font to render with, set for saved jobs
+
+	tabTemplate   *tabtemplate.Template
+	qrTemplate    *tabtemplate.Template
+	boundaryStats []string
+}
+
+// Build generates the separators for cards and lays them out on pages
+func Build(cards []database.Card, cfg PrintConfig) (*Layout, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	page := cfg.Page()
+	card := cfg.Card()
+	imp := Impose(cfg.ImpositionParams())
+	if imp.PerPage == 0 {
+		return nil, fmt.Errorf("no separators fit on a %gx%gmm page", page.Width, page.Height)
+	}
+	tmpl, err := cfg.Template()
+	if err != nil {
+		return nil, err
+	}
+	qr, err := cfg.QRCode.Template()
+	if err != nil {
+		return nil, err
+	}
+	grid := Grid{CardsPerRow: imp.Columns, RowsPerPage: imp.Rows, CardsPerPage: imp.PerPage}
+
+	separators, err := GenerateBoundedSeparatorPairs(cards, cfg.Boundary)
+	if err != nil {
+		return nil, err
+	}
+	l := &Layout{
+		Config:         cfg,
+		Page:           page,
+		Card:           card,
+		Grid:           grid,
+		Imposition:     imp,
+		CardCount:      len(cards),
+		SeparatorCount: len(separators),
+		Pages:          []Page{},
+		tabTemplate:    tmpl,
+		qrTemplate:     qr,
+	}
+	if cfg.RegistrationMarks {
+		l.Registration = RegistrationMarks(page)
+	}
+	if cfg.Boundary.Mode == BoundaryContent && cfg.Boundary.Stats {
+		l.boundaryStats = BoundaryStats(cards)
+	}
+
+	for sheet, chunk := range ChunkSeparators(separators, grid.CardsPerPage) {
+		front := make([]int, len(chunk))
+		for i := range chunk {
+			front[i] = i
+		}
+		l.addPage(sheet+1, PageFront, chunk, front)
+
+		if cfg.DoubleSided {
+			// Each back goes behind its front, so a partial page's backs
+			// are not packed into the first slots
+			back := make([]int, len(chunk))
+			for i := range chunk {
+				back[i] = BackSlot(i, cfg.FlipEdge, grid)
+			}
+			l.addPage(sheet+1, PageBack, chunk, back)
+		}
+		l.SheetCount = sheet + 1
+	}
+
+	return l, nil
+}
+
+// Trim returns the placement's trim box
+func (pl Placement) Trim() Box {
+	return Box{X: pl.X, Y: pl.Y, Width: pl.Width, Height: pl.Height}
+}
+
+// Face returns the placement turned upright: the trim box keeps its top-left
+// corner but takes the card's own width and height. Renderers draw this and
+// rotate it into place by Rotation degrees clockwise.
+func (pl Placement) Face() Placement {
+	if pl.Rotation == 0 {
+		return pl
+	}
+	face := pl
+	face.Width, face.Height = pl.Height, pl.Width
+	bleed := pl.X - pl.BleedBox.X
+	safe := pl.SafeBox.X - pl.X
+	face.BleedBox = face.Trim().Inset(-bleed)
+	face.SafeBox = face.Trim().Inset(safe)
+	face.Rotation = 0
+	return face
+}
+
+// PageByNumber returns the 1-indexed page n of the layout
+func (l *Layout) PageByNumber(n int) (*Page, bool) {
+	if n < 1 || n > len(l.Pages) {
+		return nil, false
+	}
+	return &l.Pages[n-1], true
+}
+
+// addPage appends a page placing separators[i] in grid slot slots[i].
+// Placements are kept in slot order.
+func (l *Layout) addPage(sheet int, pageType PageType, separators []SeparatorPair, slots []int) {
+	p := Page{
+		Number:     len(l.Pages) + 1,
+		Sheet:      sheet,
+		Type:       pageType,
+		Placements: make([]Placement, 0, len(separators)),
+	}
+
+	order := make([]int, len(separators))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return slots[order[a]] < slots[order[b]] })
+
+	imp := l.Imposition
+	spacing := l.Config.Spacing()
+	width, height := l.Card.Width, l.Card.Height
+	rotation := 0
+	if imp.Rotated {
+		width, height = height, width
+		// Back faces turn the other way so their tabs line up with the fronts
+		rotation = 90
+		if pageType == PageBack {
+			rotation = 270
+		}
+	}
+
+	for _, i := range order {
+		sep, slot := separators[i], slots[i]
+		face := sep.Front
+		if pageType == PageBack {
+			face = sep.Back
+		}
+		row := slot / l.Grid.CardsPerRow
+		col := slot % l.Grid.CardsPerRow
+		trim := Box{
+			X:      imp.OriginX + float64(col)*imp.PitchX + spacing,
+			Y:      imp.OriginY + float64(row)*imp.PitchY + spacing,
+			Width:  width,
+			Height: height,
+		}
+		pl := Placement{
+			Slot:      slot,
+			Row:       row,
+			Column:    col,
+			X:         trim.X,
+			Y:         trim.Y,
+			Width:     trim.Width,
+			Height:    trim.Height,
+			Rotation:  rotation,
+			Tab:       TabIndex(sep.Position, l.Config.TabCount(), pageType),
+			BleedBox:  trim.Inset(-l.Config.Bleed),
+			SafeBox:   trim.Inset(l.Config.SafeMargin),
+			Separator: sep.Position,
+			Blank:     IsBlank(face),
+			Header:    IsSetHeader(face),
+			Label:     TabLabel(face, l.tabTemplate),
+			QRCode:    QRContent(face, l.qrTemplate, l.Config.QRCode.Capacity()),
+			Card:      face,
+		}
+		if IsBlank(face) {
+			pl.Details = l.boundaryStats
+		}
+
+		if len(p.Placements) == 0 {
+			p.TrimBox, p.BleedBox = trim, pl.BleedBox
+		} else {
+			p.TrimBox = p.TrimBox.Union(trim)
+			p.BleedBox = p.BleedBox.Union(pl.BleedBox)
+		}
+		p.Placements = append(p.Placements, pl)
+	}
+
+	l.Pages = append(l.Pages, p)
+}
@@ -0,0 +1,110 @@
+package layout
+
+import (
+	"card-separator/database"
+	"card-separator/tabtemplate"
+	"fmt"
+	"math"
+)
+
+// QR code sizes in millimetres. MinQRSize fits a version 1 code, up to 14
+// bytes, with modules of MinQRModule.
+const (
+	DefaultQRSize = 12
+	MinQRSize     = 8
+	MaxQRSize     = 30
+)
+
+// MinQRModule is the smallest printed module in millimetres phone cameras
+// resolve reliably. A code's capacity is capped so its modules, quiet zone
+// included, are no smaller.
+const MinQRModule = 0.25
+
+// qrQuietZone is the margin in modules on each side of a code
+const qrQuietZone = 4
+
+// qrByteCapacity is the byte-mode capacity of QR versions 1-10 at medium
+// error correction. Larger versions hold more than MaxQRContent.
+var qrByteCapacity = [...]int{14, 26, 42, 62, 84, 106, 122, 152, 180, 213}
+
+// DefaultQRContent is the QR template used when none is set: the card ID
+const DefaultQRContent = "{card_set_id}"
+
+// MaxQRContent is the most bytes a code of any size may carry. Smaller
+// codes carry less, see QRCode.Capacity.
+const MaxQRContent = 200
+
+// QRCode configures the QR code printed on each card's separator faces
+type QRCode struct {
+	Enabled bool    `json:"enabled"`
+	Content string  `json:"content,omitempty"` // Template like tab_template, e.g. "https://example.com/api/cards/{card_set_id}"
+	Size    float64 `json:"size_mm,omitempty"` // Side of the code including its quiet zone, DefaultQRSize when 0
+}
+
+// Validate checks the QR size and content template
+func (q QRCode) Validate() error {
+	if !q.Enabled {
+		return nil
+	}
+	if q.Size != 0 && (q.Size < MinQRSize || q.Size > MaxQRSize) {
+		return fmt.Errorf("qr_code size_mm must be between %d and %d", MinQRSize, MaxQRSize)
+	}
+	tmpl, err := q.Template()
+	if err != nil {
+		return fmt.Errorf("invalid qr_code content: %w", err)
+	}
+	// The fixed text of the template must fit before any card fields do
+	if fixed, capacity := len(tmpl.Execute(database.Card{})), q.Capacity(); fixed > capacity {
+		return fmt.Errorf("qr_code content has %d bytes of fixed text, but a %gmm code fits at most %d: shorten the content or raise size_mm",
+			fixed, q.SideMM(), capacity)
+	}
+	return nil
+}
+
+// Template parses the content template, returning nil when codes are off
+func (q QRCode) Template() (*tabtemplate.Template, error) {
+	if !q.Enabled {
+		return nil, nil
+	}
+	content := q.Content
+	if content == "" {
+		content = DefaultQRContent
+	}
+	return tabtemplate.Parse(content)
+}
+
+// SideMM returns the printed size of the code
+func (q QRCode) SideMM() float64 {
+	if q.Size == 0 {
+		return DefaultQRSize
+	}
+	return q.Size
+}
+
+// Capacity returns the most bytes a code of this size carries with modules
+// of at least MinQRModule, up to MaxQRContent
+func (q QRCode) Capacity() int {
+	modules := int(math.Floor(q.SideMM()/MinQRModule + 1e-9))
+	version := (modules - 2*qrQuietZone - 17) / 4
+	switch {
+	case version < 1:
+		return 0
+	case version > len(qrByteCapacity):
+		return MaxQRContent
+	}
+	return min(qrByteCapacity[version-1], MaxQRContent)
+}
+
+// QRContent returns what a separator face's QR code encodes. Only real cards
+// get a code: boundaries, set headers and groups have no card to link to.
+// Content that renders empty or longer than capacity bytes is left out.
+func QRContent(card database.Card, tmpl *tabtemplate.Template, capacity int) string {
+	if tmpl == nil || IsBlank(card) || IsSetHeader(card) || IsGroup(card) {
+		return ""
+	}
+	content := tmpl.Execute(card)
+	if len(content) > capacity {
+		return ""
+	}
+	return content
+}
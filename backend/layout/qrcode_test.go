@@ -0,0 +1,41 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQRCodeValidateCapacity(t *testing.T) {
+	url := "https://example.com/api/cards/{card_set_id}"
+	tests := []struct {
+		content string
+		size    float64
+		ok      bool
+	}{
+		{"", MinQRSize, true},
+		{url, MinQRSize, false},
+		{url, DefaultQRSize, true},
+		{strings.Repeat("x", MaxQRContent+1), MaxQRSize, false},
+	}
+
+	for _, tt := range tests {
+		err := QRCode{Enabled: true, Content: tt.content, Size: tt.size}.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("Validate(%q, %gmm) = %v, want ok %v", tt.content, tt.size, err, tt.ok)
+		}
+	}
+}
+
+func TestQRContentCapacity(t *testing.T) {
+	tmpl, err := QRCode{Enabled: true}.Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := testCards(1)[0]
+	if got := QRContent(card, tmpl, 14); got != "C1" {
+		t.Errorf("QRContent = %q, want C1", got)
+	}
+	if got := QRContent(card, tmpl, 1); got != "" {
+		t.Errorf("QRContent over capacity = %q, want none", got)
+	}
+}
@@ -0,0 +1,45 @@
+package layout
+
+// Registration mark geometry in millimetres, matching the Type 1 marks of
+// Silhouette Studio: a filled square in the top-left corner and L-shaped
+// corners in the top-right and bottom-left, inset from the page edges
+const (
+	RegistrationInset     = 10  // From the page edges to the marks
+	RegistrationSize      = 5   // Side of the square mark
+	RegistrationLength    = 20  // Arm length of the corner marks
+	RegistrationWeight    = 0.5 // Arm width of the corner marks
+	RegistrationClearance = 2   // Kept clear between the marks and the grid
+)
+
+// RegistrationSpace is the margin reserved on every page edge when
+// registration marks are enabled
+const RegistrationSpace = RegistrationInset + RegistrationSize + RegistrationClearance
+
+// RegistrationMarks returns the filled boxes of the print-and-cut
+// registration marks for a page. The same boxes are printed on front pages
+// and written to the cut files, so a cutter that reads the printed marks
+// lines its cuts up with the print.
+func RegistrationMarks(page PageDimensions) []Box {
+	const i, l, w = RegistrationInset, RegistrationLength, RegistrationWeight
+	right, bottom := page.Width-i, page.Height-i
+	return []Box{
+		// Top-left square
+		{X: i, Y: i, Width: RegistrationSize, Height: RegistrationSize},
+		// Top-right corner
+		{X: right - l, Y: i, Width: l, Height: w},
+		{X: right - w, Y: i, Width: w, Height: l},
+		// Bottom-left corner
+		{X: i, Y: bottom - w, Width: l, Height: w},
+		{X: i, Y: bottom - l, Width: w, Height: l},
+	}
+}
+
+// FrontPage returns the front page of the 1-indexed sheet
+func (l *Layout) FrontPage(sheet int) (*Page, bool) {
+	for i := range l.Pages {
+		if l.Pages[i].Sheet == sheet && l.Pages[i].Type == PageFront {
+			return &l.Pages[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,229 @@
+// Package layout implements the separator pairing and page layout rules
+// from web/src/lib/separatorLogic.ts so that any client can get the same
+// print layout without running the Svelte app.
+//
+// Physical layout: |1 1|2 2|3 3|4 4|5
+//
+// For N cards, we need N+1 separators:
+//   - Separator 1: Front=Card1, Back=blank
+//   - Separator 2: Front=Card2, Back=Card1
+//   - ...
+//   - Separator N: Front=CardN, Back=CardN-1
+//   - Separator N+1: Front=blank, Back=CardN
+package layout
+
+import (
+	"card-separator/database"
+	"fmt"
+	"strings"
+)
+
+// BlankCardID identifies the placeholder used for collection boundaries
+const BlankCardID = "---"
+
+// SetHeaderPrefix starts the CardSetID of set header placeholders
+const SetHeaderPrefix = "set:"
+
+// PageType is the side of the sheet a print page is for
+type PageType string
+
+const (
+	PageFront PageType = "front"
+	PageBack  PageType = "back"
+)
+
+// SeparatorPair is a single physical separator
+type SeparatorPair struct {
+	Position int           `json:"position"` // 0-indexed position
+	Front    database.Card `json:"front"`    // Blank card for collection boundaries
+	Back     database.Card `json:"back"`     // Blank card for collection boundaries
+}
+
+// PrintPage is one side of a printed sheet. Back pages are indexed by slot
+// and hold zero cards in unused slots.
+type PrintPage struct {
+	Type  PageType        `json:"type"`
+	Cards []database.Card `json:"cards"`
+}
+
+// GenerateSeparatorPairs returns N+1 separator pairs for N cards
+func GenerateSeparatorPairs(cards []database.Card) []SeparatorPair {
+	return pairSeparators(cards, CreateBlankCard())
+}
+
+// GenerateBoundedSeparatorPairs returns the separator pairs for cards with
+// the boundary faces set by boundary: N+1 pairs with blank or content
+// boundaries, or the N-1 pairs between cards when boundaries are dropped.
+// Dropping them fails with ErrTooFewCards for fewer than two cards.
+func GenerateBoundedSeparatorPairs(cards []database.Card, boundary Boundary) ([]SeparatorPair, error) {
+	if boundary.Mode != BoundaryNone {
+		return pairSeparators(cards, boundary.Card(cards)), nil
+	}
+	if len(cards) < 2 {
+		return nil, ErrTooFewCards
+	}
+	separators := pairSeparators(cards, CreateBlankCard())
+	separators = separators[1 : len(separators)-1]
+	for i := range separators {
+		separators[i].Position = i
+	}
+	return separators, nil
+}
+
+// pairSeparators returns N+1 separator pairs for N cards with blankCard on
+// the outer faces
+func pairSeparators(cards []database.Card, blankCard database.Card) []SeparatorPair {
+	if len(cards) == 0 {
+		return nil
+	}
+
+	separators := make([]SeparatorPair, 0, len(cards)+1)
+
+	// First separator: Front=Card1, Back=blank
+	separators = append(separators, SeparatorPair{
+		Position: 0,
+		Front:    cards[0],
+		Back:     blankCard,
+	})
+
+	// Middle separators: Front=CardN, Back=CardN-1
+	for i := 1; i < len(cards); i++ {
+		separators = append(separators, SeparatorPair{
+			Position: i,
+			Front:    cards[i],
+			Back:     cards[i-1],
+		})
+	}
+
+	// Last separator: Front=blank, Back=CardN (last card)
+	separators = append(separators, SeparatorPair{
+		Position: len(cards),
+		Front:    blankCard,
+		Back:     cards[len(cards)-1],
+	})
+
+	return separators
+}
+
+// TabIndex returns which of tabs positions the tab of a separator face is
+// cut at, counted from the left of the upright face. Consecutive separators
+// step through the positions so every label stays visible in a box. The
+// back face sees the same cut from behind, so its index is mirrored.
+func TabIndex(position, tabs int, side PageType) int {
+	if tabs < 2 {
+		return 0
+	}
+	tab := position % tabs
+	if side == PageBack {
+		tab = tabs - 1 - tab
+	}
+	return tab
+}
+
+// CreateBlankCard creates a blank card placeholder for collection boundaries
+func CreateBlankCard() database.Card {
+	return database.Card{
+		CardSetID: BlankCardID,
+		CardName:  "Collection Boundary",
+	}
+}
+
+// IsBlank reports whether a card is a collection boundary placeholder
+func IsBlank(card database.Card) bool {
+	return card.CardSetID == BlankCardID
+}
+
+// CreateSetHeader creates the placeholder that opens a set in a multi-set
+// collection. It pairs like any card, so its separator sits between the
+// previous set's last card and the set's first card.
+func CreateSetHeader(set database.Set, cardCount int) database.Card {
+	name := set.SetName
+	if name == "" {
+		name = set.SetID
+	}
+	return database.Card{
+		CardSetID: SetHeaderPrefix + set.SetID,
+		CardName:  fmt.Sprintf("%s · %d cards", name, cardCount),
+		SetID:     set.SetID,
+		SetName:   set.SetName,
+	}
+}
+
+// IsSetHeader reports whether a card is a set header placeholder
+func IsSetHeader(card database.Card) bool {
+	return strings.HasPrefix(card.CardSetID, SetHeaderPrefix)
+}
+
+// ChunkSeparators splits separators into pages
+func ChunkSeparators(separators []SeparatorPair, separatorsPerPage int) [][]SeparatorPair {
+	var chunks [][]SeparatorPair
+	if separatorsPerPage <= 0 {
+		return chunks
+	}
+	for i := 0; i < len(separators); i += separatorsPerPage {
+		end := i + separatorsPerPage
+		if end > len(separators) {
+			end = len(separators)
+		}
+		chunks = append(chunks, separators[i:end])
+	}
+	return chunks
+}
+
+// ApplyFlipTransformation places back face cards for double-sided printing.
+// backCards are in front slot order; the result is indexed by back slot and
+// always covers the full grid, with zero cards in slots no separator uses.
+// Long edge (book flip) mirrors each row; short edge (calendar flip) rotates
+// the entire page.
+func ApplyFlipTransformation(backCards []database.Card, flipEdge FlipEdge, grid Grid) []database.Card {
+	flipped := make([]database.Card, grid.CardsPerPage)
+	for slot, card := range backCards {
+		flipped[BackSlot(slot, flipEdge, grid)] = card
+	}
+	return flipped
+}
+
+// BackSlot returns the slot on the back of a sheet that lies behind front
+// slot of grid. Slots are counted on the full grid, so a partial last page
+// keeps every back behind its front.
+func BackSlot(slot int, flipEdge FlipEdge, grid Grid) int {
+	row := slot / grid.CardsPerRow
+	col := grid.CardsPerRow - 1 - slot%grid.CardsPerRow
+	if flipEdge != FlipLong {
+		// Short edge flip: the page turns over top to bottom as well
+		row = grid.RowsPerPage - 1 - row
+	}
+	return row*grid.CardsPerRow + col
+}
+
+// GeneratePrintPages builds the print pages for separator pairs, interleaving
+// flipped back pages when printing double-sided
+func GeneratePrintPages(separators []SeparatorPair, grid Grid, flipEdge FlipEdge, doubleSided bool) []PrintPage {
+	if len(separators) == 0 {
+		return nil
+	}
+
+	var pages []PrintPage
+	for _, chunk := range ChunkSeparators(separators, grid.CardsPerPage) {
+		// Front page: show the front face of each separator
+		frontCards := make([]database.Card, len(chunk))
+		for i, sep := range chunk {
+			frontCards[i] = sep.Front
+		}
+		pages = append(pages, PrintPage{Type: PageFront, Cards: frontCards})
+
+		if doubleSided {
+			// Back page: show the back face of each separator
+			backCards := make([]database.Card, len(chunk))
+			for i, sep := range chunk {
+				backCards[i] = sep.Back
+			}
+			pages = append(pages, PrintPage{
+				Type:  PageBack,
+				Cards: ApplyFlipTransformation(backCards, flipEdge, grid),
+			})
+		}
+	}
+
+	return pages
+}
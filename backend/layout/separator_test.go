@@ -0,0 +1,303 @@
+package layout
+
+import (
+	"card-separator/database"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// testCards returns n cards with IDs C1..Cn
+func testCards(n int) []database.Card {
+	cards := make([]database.Card, n)
+	for i := range cards {
+		cards[i] = database.Card{CardSetID: fmt.Sprintf("C%d", i+1), CardName: fmt.Sprintf("Card %d", i+1)}
+	}
+	return cards
+}
+
+// cardIDs returns the CardSetID of each card, "" for zero cards
+func cardIDs(cards []database.Card) []string {
+	ids := make([]string, len(cards))
+	for i, c := range cards {
+		ids[i] = c.CardSetID
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGenerateSeparatorPairs(t *testing.T) {
+	tests := []struct {
+		name   string
+		cards  int
+		fronts []string
+		backs  []string
+	}{
+		{"empty", 0, []string{}, []string{}},
+		{"one card", 1, []string{"C1", BlankCardID}, []string{BlankCardID, "C1"}},
+		{"three cards", 3, []string{"C1", "C2", "C3", BlankCardID}, []string{BlankCardID, "C1", "C2", "C3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs := GenerateSeparatorPairs(testCards(tt.cards))
+			if len(pairs) != len(tt.fronts) {
+				t.Fatalf("got %d pairs, want %d", len(pairs), len(tt.fronts))
+			}
+			fronts := make([]string, len(pairs))
+			backs := make([]string, len(pairs))
+			for i, p := range pairs {
+				if p.Position != i {
+					t.Errorf("pair %d has position %d", i, p.Position)
+				}
+				fronts[i], backs[i] = p.Front.CardSetID, p.Back.CardSetID
+			}
+			if !equalStrings(fronts, tt.fronts) {
+				t.Errorf("fronts = %v, want %v", fronts, tt.fronts)
+			}
+			if !equalStrings(backs, tt.backs) {
+				t.Errorf("backs = %v, want %v", backs, tt.backs)
+			}
+		})
+	}
+}
+
+func TestGenerateBoundedSeparatorPairsNone(t *testing.T) {
+	tests := []struct {
+		name   string
+		cards  int
+		fronts []string
+		err    error
+	}{
+		{"empty", 0, nil, ErrTooFewCards},
+		{"one card", 1, nil, ErrTooFewCards},
+		{"two cards", 2, []string{"C2"}, nil},
+		{"three cards", 3, []string{"C2", "C3"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, err := GenerateBoundedSeparatorPairs(testCards(tt.cards), Boundary{Mode: BoundaryNone})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			fronts := make([]string, len(pairs))
+			for i, p := range pairs {
+				if p.Position != i {
+					t.Errorf("pair %d has position %d", i, p.Position)
+				}
+				fronts[i] = p.Front.CardSetID
+			}
+			if len(pairs) != len(tt.fronts) || (len(pairs) > 0 && !equalStrings(fronts, tt.fronts)) {
+				t.Errorf("fronts = %v, want %v", fronts, tt.fronts)
+			}
+		})
+	}
+
+	cfg := DefaultPrintConfig()
+	cfg.Boundary.Mode = BoundaryNone
+	if _, err := Build(testCards(1), cfg); !errors.Is(err, ErrTooFewCards) {
+		t.Errorf("Build with one card = %v, want ErrTooFewCards", err)
+	}
+}
+
+func TestChunkSeparators(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		perPage int
+		sizes   []int
+	}{
+		{"empty", 0, 9, nil},
+		{"partial page", 4, 9, []int{4}},
+		{"exact page", 9, 9, []int{9}},
+		{"one over", 10, 9, []int{9, 1}},
+		{"two full pages", 18, 9, []int{9, 9}},
+		{"no slots", 5, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			separators := make([]SeparatorPair, tt.n)
+			for i := range separators {
+				separators[i].Position = i
+			}
+			chunks := ChunkSeparators(separators, tt.perPage)
+			if len(chunks) != len(tt.sizes) {
+				t.Fatalf("got %d chunks, want %d", len(chunks), len(tt.sizes))
+			}
+			next := 0
+			for i, chunk := range chunks {
+				if len(chunk) != tt.sizes[i] {
+					t.Errorf("chunk %d has %d separators, want %d", i, len(chunk), tt.sizes[i])
+				}
+				for _, sep := range chunk {
+					if sep.Position != next {
+						t.Errorf("chunk %d holds position %d, want %d", i, sep.Position, next)
+					}
+					next++
+				}
+			}
+		})
+	}
+}
+
+func TestApplyFlipTransformation(t *testing.T) {
+	grid := Grid{CardsPerRow: 3, RowsPerPage: 3, CardsPerPage: 9}
+
+	tests := []struct {
+		name  string
+		cards int
+		edge  FlipEdge
+		want  []string
+	}{
+		{"long full", 9, FlipLong, []string{"C3", "C2", "C1", "C6", "C5", "C4", "C9", "C8", "C7"}},
+		{"short full", 9, FlipShort, []string{"C9", "C8", "C7", "C6", "C5", "C4", "C3", "C2", "C1"}},
+		{"long partial row", 2, FlipLong, []string{"", "C2", "C1", "", "", "", "", "", ""}},
+		{"long partial page", 5, FlipLong, []string{"C3", "C2", "C1", "", "C5", "C4", "", "", ""}},
+		{"short partial row", 2, FlipShort, []string{"", "", "", "", "", "", "", "C2", "C1"}},
+		{"short partial page", 4, FlipShort, []string{"", "", "", "", "", "C4", "C3", "C2", "C1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cardIDs(ApplyFlipTransformation(testCards(tt.cards), tt.edge, grid))
+			if !equalStrings(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTabIndex(t *testing.T) {
+	tests := []struct {
+		position, tabs int
+		side           PageType
+		want           int
+	}{
+		{0, 0, PageFront, 0},
+		{5, 1, PageBack, 0},
+		{0, 3, PageFront, 0},
+		{1, 3, PageFront, 1},
+		{2, 3, PageFront, 2},
+		{3, 3, PageFront, 0},
+		{0, 3, PageBack, 2},
+		{1, 3, PageBack, 1},
+		{4, 3, PageBack, 1},
+		{5, 2, PageBack, 0},
+	}
+
+	for _, tt := range tests {
+		if got := TabIndex(tt.position, tt.tabs, tt.side); got != tt.want {
+			t.Errorf("TabIndex(%d, %d, %s) = %d, want %d", tt.position, tt.tabs, tt.side, got, tt.want)
+		}
+	}
+}
+
+func TestBuildDuplexRegistration(t *testing.T) {
+	tests := []struct {
+		name  string
+		cards int
+		edge  FlipEdge
+	}{
+		{"long full pages", 17, FlipLong},
+		{"short full pages", 17, FlipShort},
+		{"long partial row", 10, FlipLong},
+		{"short partial row", 10, FlipShort},
+		{"long partial page", 11, FlipLong},
+		{"short partial page", 11, FlipShort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultPrintConfig()
+			cfg.DoubleSided = true
+			cfg.FlipEdge = tt.edge
+			l, err := Build(testCards(tt.cards), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.Grid.CardsPerRow != 3 || l.Grid.RowsPerPage != 3 {
+				t.Fatalf("expected an A4 3x3 grid, got %+v", l.Grid)
+			}
+			if len(l.Pages) != 2*l.SheetCount {
+				t.Fatalf("got %d pages for %d sheets", len(l.Pages), l.SheetCount)
+			}
+
+			rows, cols := l.Grid.RowsPerPage, l.Grid.CardsPerRow
+			for i := 0; i < len(l.Pages); i += 2 {
+				front, back := l.Pages[i], l.Pages[i+1]
+				if front.Type != PageFront || back.Type != PageBack {
+					t.Fatalf("sheet %d pages are %s, %s", front.Sheet, front.Type, back.Type)
+				}
+				if len(front.Placements) != len(back.Placements) {
+					t.Fatalf("sheet %d has %d fronts and %d backs", front.Sheet, len(front.Placements), len(back.Placements))
+				}
+
+				backs := make(map[int]Placement, len(back.Placements))
+				for j, pl := range back.Placements {
+					if j > 0 && back.Placements[j-1].Slot >= pl.Slot {
+						t.Errorf("sheet %d back placements are not in slot order", back.Sheet)
+					}
+					backs[pl.Separator] = pl
+				}
+				for _, f := range front.Placements {
+					b, ok := backs[f.Separator]
+					if !ok {
+						t.Fatalf("sheet %d: separator %d has no back", front.Sheet, f.Separator)
+					}
+					wantRow, wantCol := f.Row, cols-1-f.Column
+					if tt.edge == FlipShort {
+						wantRow = rows - 1 - f.Row
+					}
+					if b.Row != wantRow || b.Column != wantCol {
+						t.Errorf("sheet %d: separator %d front r%dc%d, back r%dc%d, want r%dc%d",
+							front.Sheet, f.Separator, f.Row, f.Column, b.Row, b.Column, wantRow, wantCol)
+					}
+					if b.Slot != b.Row*cols+b.Column {
+						t.Errorf("sheet %d: separator %d back slot %d is not r%dc%d", front.Sheet, f.Separator, b.Slot, b.Row, b.Column)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildShortEdgePartialSheet(t *testing.T) {
+	cfg := DefaultPrintConfig()
+	cfg.DoubleSided = true
+	cfg.FlipEdge = FlipShort
+	l, err := Build(testCards(11), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 12 separators: the second sheet holds positions 9-11 in its top row,
+	// so their backs sit in the bottom row, mirrored
+	back := l.Pages[3]
+	want := map[int][2]int{9: {2, 2}, 10: {2, 1}, 11: {2, 0}}
+	if len(back.Placements) != len(want) {
+		t.Fatalf("got %d back placements, want %d", len(back.Placements), len(want))
+	}
+	for _, pl := range back.Placements {
+		rc, ok := want[pl.Separator]
+		if !ok {
+			t.Errorf("unexpected separator %d on the back", pl.Separator)
+			continue
+		}
+		if pl.Row != rc[0] || pl.Column != rc[1] {
+			t.Errorf("separator %d back at r%dc%d, want r%dc%d", pl.Separator, pl.Row, pl.Column, rc[0], rc[1])
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Package optcgstub is a stand-in for the OPTCG API serving recorded
+// fixtures, for offline development and tests. It serves /api/allSets/ and
+// /api/sets/{set_id}/ like the real API, with ETags for conditional
+// requests, and can inject latency, server errors and malformed JSON.
+// Mount it with httptest.NewServer in tests, or run cmd/optcg-stub and
+// point OPTCG_API_URL at its /api.
+package optcgstub
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// fixtures are the recorded API responses: allSets.json and
+// sets/{set_id}.json
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixtures returns the bundled recorded responses
+func Fixtures() fs.FS {
+	sub, _ := fs.Sub(fixtures, "fixtures")
+	return sub
+}
+
+// Faults are the failures the stub injects into API responses
+type Faults struct {
+	Latency       time.Duration // Added before every response
+	ErrorRate     float64       // Share of requests, 0 to 1, answered with ErrorStatus
+	ErrorStatus   int           // 503 when unset
+	MalformedRate float64       // Share of requests, 0 to 1, answered with cut-off JSON
+}
+
+// faultsJSON is Faults as read and written by /_stub/faults
+type faultsJSON struct {
+	LatencyMS     int64   `json:"latency_ms"`
+	ErrorRate     float64 `json:"error_rate"`
+	ErrorStatus   int     `json:"error_status,omitempty"`
+	MalformedRate float64 `json:"malformed_rate"`
+}
+
+// MarshalJSON writes the faults with latency in milliseconds
+func (f Faults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(faultsJSON{
+		LatencyMS:     f.Latency.Milliseconds(),
+		ErrorRate:     f.ErrorRate,
+		ErrorStatus:   f.ErrorStatus,
+		MalformedRate: f.MalformedRate,
+	})
+}
+
+// UnmarshalJSON reads faults with latency in milliseconds
+func (f *Faults) UnmarshalJSON(data []byte) error {
+	var v faultsJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*f = Faults{
+		Latency:       time.Duration(v.LatencyMS) * time.Millisecond,
+		ErrorRate:     v.ErrorRate,
+		ErrorStatus:   v.ErrorStatus,
+		MalformedRate: v.MalformedRate,
+	}
+	return nil
+}
+
+// Validate checks the rates and status are usable
+func (f Faults) Validate() error {
+	if f.Latency < 0 {
+		return fmt.Errorf("latency must be non-negative")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 || f.MalformedRate < 0 || f.MalformedRate > 1 {
+		return fmt.Errorf("rates must be between 0 and 1")
+	}
+	if f.ErrorStatus != 0 && (f.ErrorStatus < 500 || f.ErrorStatus > 599) {
+		return fmt.Errorf("error_status must be a 5xx status")
+	}
+	return nil
+}
+
+// Server serves fixtures as the OPTCG API
+type Server struct {
+	fixtures fs.FS
+	router   *mux.Router
+
+	mu       sync.Mutex
+	faults   Faults
+	random   *rand.Rand
+	requests map[string]int
+}
+
+// New creates a stub serving fixtures laid out like Fixtures
+func New(fixtures fs.FS) *Server {
+	s := &Server{
+		fixtures: fixtures,
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		requests: make(map[string]int),
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/api/allSets/", s.serveFixture(func(map[string]string) string { return "allSets.json" })).Methods("GET")
+	r.HandleFunc("/api/sets/{set_id}/", s.serveFixture(func(vars map[string]string) string {
+		return "sets/" + vars["set_id"] + ".json"
+	})).Methods("GET")
+	r.HandleFunc("/_stub/faults", s.getFaults).Methods("GET")
+	r.HandleFunc("/_stub/faults", s.putFaults).Methods("PUT")
+	s.router = r
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// SetFaults replaces the injected faults
+func (s *Server) SetFaults(f Faults) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = f
+	return nil
+}
+
+// Faults returns the injected faults
+func (s *Server) Faults() Faults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.faults
+}
+
+// Requests returns how many API requests were made for path, including
+// failed ones
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// fault decides what to inject into one response
+type fault int
+
+const (
+	faultNone fault = iota
+	faultError
+	faultMalformed
+)
+
+// next counts a request and picks its fault
+func (s *Server) next(path string) (Faults, fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[path]++
+	f := s.faults
+	switch roll := s.random.Float64(); {
+	case roll < f.ErrorRate:
+		return f, faultError
+	case roll < f.ErrorRate+f.MalformedRate:
+		return f, faultMalformed
+	}
+	return f, faultNone
+}
+
+// serveFixture serves the fixture named by the route variables, with
+// injected faults
+func (s *Server) serveFixture(name func(vars map[string]string) string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		faults, injected := s.next(r.URL.Path)
+		if faults.Latency > 0 {
+			select {
+			case <-time.After(faults.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if injected == faultError {
+			status := faults.ErrorStatus
+			if status == 0 {
+				status = http.StatusServiceUnavailable
+			}
+			log.Printf("[STUB] %s: injected %d", r.URL.Path, status)
+			writeJSONError(w, status, http.StatusText(status))
+			return
+		}
+
+		file := name(mux.Vars(r))
+		data, err := fs.ReadFile(s.fixtures, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			writeJSONError(w, http.StatusNotFound, "Not found.")
+			return
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if injected == faultMalformed {
+			log.Printf("[STUB] %s: injected malformed JSON", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(data[:len(data)/2])
+			return
+		}
+
+		// Answer conditional requests like the API's cache headers allow
+		var modified time.Time
+		if info, err := fs.Stat(s.fixtures, file); err == nil {
+			modified = info.ModTime()
+		}
+		sum := sha256.Sum256(data)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeContent(w, r, file, modified, bytes.NewReader(data))
+	}
+}
+
+// getFaults handles GET /_stub/faults
+func (s *Server) getFaults(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Faults())
+}
+
+// putFaults handles PUT /_stub/faults, replacing the injected faults
+func (s *Server) putFaults(w http.ResponseWriter, r *http.Request) {
+	var f Faults
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := s.SetFaults(f); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("[STUB] Faults set: %+v", f)
+	s.getFaults(w, r)
+}
+
+// writeJSONError writes an error body shaped like the API's
+func writeJSONError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"detail": detail})
+}
@@ -0,0 +1,267 @@
+// Package preset defines saved print presets in the web editor's preset
+// export format (web/src/lib/Presets.svelte), with the tab and visual
+// settings the editor keeps outside AppConfig.
+package preset
+
+import (
+	"card-separator/layout"
+	"card-separator/tabtemplate"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Version is the preset export format version written by this package.
+// Exports from before versioning carry no version and are read as version 1.
+const Version = 1
+
+// MaxNameLength is the longest preset name in characters
+const MaxNameLength = 100
+
+// ImageFilters are the image filter presets of the editor's visual settings
+var ImageFilters = []string{"none", "grayscale", "sepia", "vintage", "blur", "contrast"}
+
+// hexColor matches #RGB and #RRGGBB colours
+var hexColor = regexp.MustCompile(`^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// Preset is a named, full editor configuration
+type Preset struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Config    Config    `json:"config"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Config mirrors AppConfig in web/src/lib/config.ts plus the editor's tab
+// and visual settings
+type Config struct {
+	SetID          string                 `json:"setId,omitempty"`
+	DoubleSided    bool                   `json:"doubleSided"`
+	FlipEdge       layout.FlipEdge        `json:"flipEdge"`
+	ShowImages     bool                   `json:"showImages"`
+	ImageQuality   string                 `json:"imageQuality"`
+	ShowCutLines   bool                   `json:"showCutLines"`
+	PageSize       string                 `json:"pageSize"`
+	CustomPageSize *layout.PageDimensions `json:"customPageSize,omitempty"`
+	CardDimensions CardDimensions         `json:"cardDimensions"`
+	PageDimensions layout.PageDimensions  `json:"pageDimensions"`
+	Colors         Colors                 `json:"colors"`
+	Tab            *Tab                   `json:"tab,omitempty"`
+	Visual         *Visual                `json:"visual,omitempty"`
+}
+
+// CardDimensions is the editor's separator size in millimetres
+type CardDimensions struct {
+	Width     float64 `json:"width"`
+	Height    float64 `json:"height"`
+	TabHeight float64 `json:"tabHeight"`
+}
+
+// Colors are the editor's tab colours
+type Colors struct {
+	Primary   string `json:"primary"`
+	Secondary string `json:"secondary"`
+}
+
+// Tab mirrors tabConfig in web/src/routes/+page.svelte
+type Tab struct {
+	FontSize    float64 `json:"fontSize"` // Pixels
+	FontFamily  string  `json:"fontFamily"`
+	OffsetX     float64 `json:"offsetX"` // Pixels
+	OffsetY     float64 `json:"offsetY"` // Pixels
+	Content     string  `json:"content"` // Tab text template
+	TextColor   string  `json:"textColor"`
+	StrokeWidth float64 `json:"strokeWidth"`
+	StrokeColor string  `json:"strokeColor"`
+}
+
+// Visual mirrors visualConfig in web/src/routes/+page.svelte
+type Visual struct {
+	BorderColor     string  `json:"borderColor"`
+	BorderWidth     float64 `json:"borderWidth"`     // Pixels
+	ImageCenterSize float64 `json:"imageCenterSize"` // Percent
+	ImageFilter     string  `json:"imageFilter"`
+}
+
+// DefaultConfig returns DEFAULT_CONFIG from web/src/lib/config.ts
+func DefaultConfig() Config {
+	card := layout.DefaultCardDimensions
+	return Config{
+		SetID:          "OP-01",
+		FlipEdge:       layout.FlipLong,
+		ShowImages:     true,
+		ImageQuality:   "medium",
+		PageSize:       "a4",
+		CardDimensions: CardDimensions{Width: card.Width, Height: card.Height, TabHeight: card.TabHeight},
+		PageDimensions: layout.PageSizes["a4"],
+		Colors:         Colors{Primary: "#DC2626", Secondary: "#B91C1C"},
+	}
+}
+
+// Decode reads a preset in the export format, filling settings it leaves
+// out from DefaultConfig. Callers validate it once its name is settled.
+func Decode(data []byte) (*Preset, error) {
+	p := Preset{Config: DefaultConfig()}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid preset: %w", err)
+	}
+	if p.Version == 0 {
+		p.Version = 1
+	}
+	if p.Version > Version {
+		return nil, fmt.Errorf("unsupported preset version %d (latest is %d)", p.Version, Version)
+	}
+	p.Version = Version
+	p.Name = strings.TrimSpace(p.Name)
+	return &p, nil
+}
+
+// Validate checks the preset's name and the ranges of its settings, which
+// match the editor's inputs
+func (p *Preset) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(p.Name) > MaxNameLength {
+		return fmt.Errorf("name must be at most %d characters", MaxNameLength)
+	}
+	return p.Config.Validate()
+}
+
+// Validate checks the config's settings are within the editor's ranges and
+// produce a printable layout
+func (c Config) Validate() error {
+	card := c.CardDimensions
+	if err := checkRange("cardDimensions.width", card.Width, 40, 100); err != nil {
+		return err
+	}
+	if err := checkRange("cardDimensions.height", card.Height, 60, 150); err != nil {
+		return err
+	}
+	if err := checkRange("cardDimensions.tabHeight", card.TabHeight, 5, 30); err != nil {
+		return err
+	}
+	if _, ok := layout.PageSizes[c.PageSize]; !ok {
+		return fmt.Errorf("invalid pageSize: %q", c.PageSize)
+	}
+	page := c.Page()
+	if err := checkRange("pageDimensions.width", page.Width, 50, 1000); err != nil {
+		return err
+	}
+	if err := checkRange("pageDimensions.height", page.Height, 50, 1000); err != nil {
+		return err
+	}
+	if err := checkColors(map[string]string{
+		"colors.primary":   c.Colors.Primary,
+		"colors.secondary": c.Colors.Secondary,
+	}); err != nil {
+		return err
+	}
+
+	if t := c.Tab; t != nil {
+		if err := checkRange("tab.fontSize", t.FontSize, 8, 24); err != nil {
+			return err
+		}
+		if err := checkRange("tab.offsetX", t.OffsetX, -10, 10); err != nil {
+			return err
+		}
+		if err := checkRange("tab.offsetY", t.OffsetY, -10, 10); err != nil {
+			return err
+		}
+		if err := checkRange("tab.strokeWidth", t.StrokeWidth, 0, 3); err != nil {
+			return err
+		}
+		if err := checkColors(map[string]string{
+			"tab.textColor":   t.TextColor,
+			"tab.strokeColor": t.StrokeColor,
+		}); err != nil {
+			return err
+		}
+		if _, err := tabtemplate.Parse(t.Content); err != nil {
+			return fmt.Errorf("invalid tab.content: %w", err)
+		}
+	}
+
+	if v := c.Visual; v != nil {
+		if err := checkRange("visual.borderWidth", v.BorderWidth, 0, 5); err != nil {
+			return err
+		}
+		if err := checkRange("visual.imageCenterSize", v.ImageCenterSize, 50, 100); err != nil {
+			return err
+		}
+		if err := checkColors(map[string]string{"visual.borderColor": v.BorderColor}); err != nil {
+			return err
+		}
+		if !validImageFilter(v.ImageFilter) {
+			return fmt.Errorf("invalid visual.imageFilter: %q", v.ImageFilter)
+		}
+	}
+
+	return c.PrintConfig().Validate()
+}
+
+// Page returns the page size the editor lays out on
+func (c Config) Page() layout.PageDimensions {
+	if c.PageSize == "custom" {
+		if c.CustomPageSize != nil {
+			return *c.CustomPageSize
+		}
+		return c.PageDimensions
+	}
+	return layout.PageSizes[c.PageSize]
+}
+
+// PrintConfig converts the preset into the layout engine's print config
+func (c Config) PrintConfig() layout.PrintConfig {
+	cfg := layout.DefaultPrintConfig()
+	cfg.DoubleSided = c.DoubleSided
+	cfg.FlipEdge = c.FlipEdge
+	cfg.ShowImages = c.ShowImages
+	cfg.ImageQuality = c.ImageQuality
+	cfg.ShowCutLines = c.ShowCutLines
+	cfg.PageSize = c.PageSize
+	if c.PageSize == "custom" {
+		page := c.Page()
+		cfg.CustomPageSize = &page
+	}
+	cfg.CardDimensions = &layout.CardDimensions{
+		Width:     c.CardDimensions.Width,
+		Height:    c.CardDimensions.Height,
+		TabHeight: c.CardDimensions.TabHeight,
+	}
+	if c.Tab != nil {
+		cfg.TabTemplate = c.Tab.Content
+	}
+	return cfg
+}
+
+// checkRange reports a value outside [min, max]
+func checkRange(field string, v, min, max float64) error {
+	if v < min || v > max {
+		return fmt.Errorf("%s must be between %g and %g", field, min, max)
+	}
+	return nil
+}
+
+// checkColors reports the first colour that is not a hex colour
+func checkColors(colors map[string]string) error {
+	for field, c := range colors {
+		if !hexColor.MatchString(c) {
+			return fmt.Errorf("invalid %s: %q", field, c)
+		}
+	}
+	return nil
+}
+
+func validImageFilter(filter string) bool {
+	for _, f := range ImageFilters {
+		if f == filter {
+			return true
+		}
+	}
+	return false
+}
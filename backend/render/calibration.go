@@ -0,0 +1,166 @@
+package render
+
+import (
+	"card-separator/layout"
+	"fmt"
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// calibrationInset is how far the corner crosshairs sit from the page edges
+const calibrationInset = 25
+
+// calibrationScale is the half-length in mm of the scales on the back page
+const calibrationScale = 10
+
+// RenderCalibration writes a two-page duplex calibration sheet. The front
+// has crosshairs; the back has offset scales centred on the points behind
+// each crosshair for the given flip edge. Holding the printed sheet to a
+// light, the front crosshair's position on the back scales reads off the
+// X/Y correction. The current profile offset is applied to the back page,
+// so the reading is what is left over: it is added to the current offset,
+// and a calibrated printer reads zero.
+func RenderCalibration(page layout.PageDimensions, flipEdge layout.FlipEdge, offset layout.Offset, w io.Writer) error {
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: page.Width, Ht: page.Height},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetCreator("Card Separator Generator", true)
+	pdf.SetTitle("Duplex Calibration Sheet", true)
+
+	points := calibrationPoints(page)
+
+	// Front: crosshairs, edge rulers and instructions
+	pdf.AddPage()
+	drawEdgeRulers(pdf, page)
+	for _, p := range points {
+		drawCrosshair(pdf, p)
+	}
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.SetXY(0, page.Height/2-40)
+	pdf.CellFormat(page.Width, 8, "Duplex Calibration - FRONT", "", 2, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetX(page.Width/2 - 70)
+	pdf.MultiCell(140, 4.5, fmt.Sprintf(
+		"Print this sheet double-sided at 100%% scale with %s-edge flip. "+
+			"Hold it up to a light and, at each crosshair, read where the front lines cross the scales on the back. "+
+			"This sheet is already shifted by the current offset (X %+.1f, Y %+.1f), so the readings are what is left over: "+
+			"add the X and Y readings (mm) to the current offset and store the sums as the printer's offset.",
+		flipEdge, offset.X, offset.Y), "", "C", false)
+
+	// Back: offset scales behind each crosshair, shifted by the current offset
+	pdf.AddPage()
+	pdf.TransformBegin()
+	pdf.TransformTranslate(offset.X, offset.Y)
+	drawEdgeRulers(pdf, page)
+	for _, p := range points {
+		drawOffsetScales(pdf, mirrorPoint(p, page, flipEdge))
+	}
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.SetXY(0, page.Height/2-40)
+	pdf.CellFormat(page.Width, 8, "Duplex Calibration - BACK", "", 2, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(page.Width, 5, "Positive X: move back content right. Positive Y: move back content down.", "", 2, "C", false, 0, "")
+	pdf.TransformEnd()
+
+	return pdf.Output(w)
+}
+
+// calibrationPoints returns the crosshair positions: centre and four corners
+func calibrationPoints(page layout.PageDimensions) []fpdf.PointType {
+	return []fpdf.PointType{
+		{X: page.Width / 2, Y: page.Height / 2},
+		{X: calibrationInset, Y: calibrationInset},
+		{X: page.Width - calibrationInset, Y: calibrationInset},
+		{X: calibrationInset, Y: page.Height - calibrationInset},
+		{X: page.Width - calibrationInset, Y: page.Height - calibrationInset},
+	}
+}
+
+// mirrorPoint returns where a front point lands on the back page, using the
+// same model as layout.BackSlot: long edge mirrors across
+// the vertical axis, short edge rotates the page 180 degrees
+func mirrorPoint(p fpdf.PointType, page layout.PageDimensions, flipEdge layout.FlipEdge) fpdf.PointType {
+	if flipEdge == layout.FlipShort {
+		return fpdf.PointType{X: page.Width - p.X, Y: page.Height - p.Y}
+	}
+	return fpdf.PointType{X: page.Width - p.X, Y: p.Y}
+}
+
+// drawCrosshair draws a fine crosshair with a circle around its centre
+func drawCrosshair(pdf *fpdf.Fpdf, p fpdf.PointType) {
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(0.1)
+	pdf.Line(p.X-calibrationScale-2, p.Y, p.X+calibrationScale+2, p.Y)
+	pdf.Line(p.X, p.Y-calibrationScale-2, p.X, p.Y+calibrationScale+2)
+	pdf.Circle(p.X, p.Y, 3, "D")
+}
+
+// drawOffsetScales draws millimetre scales along both axes through p,
+// labelled from -calibrationScale to +calibrationScale
+func drawOffsetScales(pdf *fpdf.Fpdf, p fpdf.PointType) {
+	pdf.SetDrawColor(220, 38, 38)
+	pdf.SetTextColor(220, 38, 38)
+	pdf.SetLineWidth(0.1)
+	pdf.SetFont("Helvetica", "", 4)
+
+	pdf.Line(p.X-calibrationScale, p.Y, p.X+calibrationScale, p.Y)
+	pdf.Line(p.X, p.Y-calibrationScale, p.X, p.Y+calibrationScale)
+
+	for i := -2 * calibrationScale; i <= 2*calibrationScale; i++ {
+		d := float64(i) / 2
+		tick := 0.75
+		if i%2 == 0 {
+			tick = 1.5
+		}
+		pdf.Line(p.X+d, p.Y-tick, p.X+d, p.Y+tick)
+		pdf.Line(p.X-tick, p.Y+d, p.X+tick, p.Y+d)
+
+		if i != 0 && i%4 == 0 {
+			label := fmt.Sprintf("%+d", i/2)
+			pdf.Text(p.X+d-pdf.GetStringWidth(label)/2, p.Y+3.5, label)
+			pdf.Text(p.X+2.2, p.Y+d+0.7, label)
+		}
+	}
+
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// drawEdgeRulers draws millimetre rulers along the top and left page edges
+func drawEdgeRulers(pdf *fpdf.Fpdf, page layout.PageDimensions) {
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetLineWidth(0.1)
+	pdf.SetFont("Helvetica", "", 5)
+
+	for x := 0; float64(x) <= page.Width; x++ {
+		length := rulerTick(x)
+		pdf.Line(float64(x), 0, float64(x), length)
+		if x > 0 && x%10 == 0 {
+			pdf.Text(float64(x)+0.5, 7, fmt.Sprint(x))
+		}
+	}
+	for y := 0; float64(y) <= page.Height; y++ {
+		length := rulerTick(y)
+		pdf.Line(0, float64(y), length, float64(y))
+		if y > 0 && y%10 == 0 {
+			pdf.Text(6, float64(y)-0.5, fmt.Sprint(y))
+		}
+	}
+}
+
+// rulerTick returns the tick length for a millimetre mark
+func rulerTick(mm int) float64 {
+	switch {
+	case mm%10 == 0:
+		return 5
+	case mm%5 == 0:
+		return 3.5
+	default:
+		return 2
+	}
+}
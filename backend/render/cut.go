@@ -0,0 +1,129 @@
+package render
+
+import (
+	"bytes"
+	"card-separator/layout"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// cutColor is the stroke colour of cut paths, which cutting software maps
+// to the cut action
+const cutColor = "#FF0000"
+
+// cutSheet returns the front page of a sheet, which the cutter reads the
+// registration marks from
+func cutSheet(l *layout.Layout, sheet int) (*layout.Page, error) {
+	page, ok := l.FrontPage(sheet)
+	if !ok {
+		return nil, fmt.Errorf("sheet %d out of range (1-%d)", sheet, l.SheetCount)
+	}
+	return page, nil
+}
+
+// RenderCutSVG writes the cut contours of a sheet's separators as an SVG
+// document in millimetre user units, the page size of the print. Cut paths
+// and registration marks sit in separate groups so cutting software can
+// keep them apart from the print layer.
+func RenderCutSVG(l *layout.Layout, sheet int, w io.Writer) error {
+	page, err := cutSheet(l, sheet)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%smm\" height=\"%smm\" viewBox=\"0 0 %s %s\">\n",
+		num(l.Page.Width), num(l.Page.Height), num(l.Page.Width), num(l.Page.Height))
+	fmt.Fprintf(&buf, "<title>Sheet %d cut contours</title>\n", sheet)
+
+	writeRegistrationMarks(&buf, l)
+
+	fmt.Fprintf(&buf, "<g id=\"cut\" fill=\"none\" stroke=\"%s\" stroke-width=\"0.1\">\n", cutColor)
+	for _, pl := range page.Placements {
+		pts := cutPath(pl, l.Card, l.Config.TabCount())
+		fmt.Fprintf(&buf, "  <path id=\"cut-%d\" data-separator=\"%d\" d=\"%s\"/>\n", pl.Slot, pl.Separator, pathData(pts))
+	}
+	buf.WriteString("</g>\n")
+
+	buf.WriteString("</svg>\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// RenderCutDXF writes the cut contours of a sheet's separators as an R12
+// DXF in millimetres, with the outlines on the CUT layer and registration
+// marks on the REGISTRATION layer. DXF puts the origin at the bottom-left,
+// so the page's top-left is at (0, page height).
+func RenderCutDXF(l *layout.Layout, sheet int, w io.Writer) error {
+	page, err := cutSheet(l, sheet)
+	if err != nil {
+		return err
+	}
+
+	d := &dxfWriter{height: l.Page.Height}
+	d.pairs("0", "SECTION", "2", "HEADER",
+		"9", "$ACADVER", "1", "AC1009",
+		"9", "$INSUNITS", "70", "4",
+		"9", "$EXTMIN", "10", "0", "20", "0",
+		"9", "$EXTMAX", "10", num(l.Page.Width), "20", num(l.Page.Height),
+		"0", "ENDSEC")
+	d.pairs("0", "SECTION", "2", "TABLES",
+		"0", "TABLE", "2", "LAYER", "70", "2",
+		"0", "LAYER", "2", "CUT", "70", "0", "62", "1", "6", "CONTINUOUS",
+		"0", "LAYER", "2", "REGISTRATION", "70", "0", "62", "7", "6", "CONTINUOUS",
+		"0", "ENDTAB", "0", "ENDSEC")
+
+	d.pairs("0", "SECTION", "2", "ENTITIES")
+	for _, m := range l.Registration {
+		r := boxRect(m)
+		d.polyline("REGISTRATION", []point{{r.X, r.Y}, {r.X + r.W, r.Y}, {r.X + r.W, r.Y + r.H}, {r.X, r.Y + r.H}})
+	}
+	for _, pl := range page.Placements {
+		d.polyline("CUT", cutPath(pl, l.Card, l.Config.TabCount()))
+	}
+	d.pairs("0", "ENDSEC", "0", "EOF")
+
+	_, err = w.Write(d.buf.Bytes())
+	return err
+}
+
+// dxfWriter writes DXF group code/value pairs, flipping Y from page
+// coordinates
+type dxfWriter struct {
+	buf    bytes.Buffer
+	height float64
+}
+
+// pairs writes alternating group codes and values, one per line
+func (d *dxfWriter) pairs(pairs ...string) {
+	for _, p := range pairs {
+		d.buf.WriteString(p)
+		d.buf.WriteString("\n")
+	}
+}
+
+// polyline writes a closed polyline on layer
+func (d *dxfWriter) polyline(layer string, pts []point) {
+	d.pairs("0", "POLYLINE", "8", layer, "66", "1", "70", "1", "10", "0", "20", "0", "30", "0")
+	for _, p := range pts {
+		d.pairs("0", "VERTEX", "8", layer, "10", num(p.X), "20", num(d.height-p.Y), "30", "0")
+	}
+	d.pairs("0", "SEQEND", "8", layer)
+}
+
+// pathData formats a closed SVG path through pts
+func pathData(pts []point) string {
+	var b strings.Builder
+	for i, p := range pts {
+		if i == 0 {
+			b.WriteString("M")
+		} else {
+			b.WriteString(" L")
+		}
+		b.WriteString(num(p.X) + " " + num(p.Y))
+	}
+	b.WriteString(" Z")
+	return b.String()
+}
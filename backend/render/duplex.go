@@ -0,0 +1,146 @@
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"card-separator/layout"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// DuplexPart is one file of a manual duplex export
+type DuplexPart string
+
+const (
+	DuplexFronts       DuplexPart = "fronts"
+	DuplexBacks        DuplexPart = "backs"
+	DuplexInstructions DuplexPart = "instructions"
+)
+
+// DuplexParts are the files of a manual duplex export in the order they are
+// used
+var DuplexParts = []DuplexPart{DuplexInstructions, DuplexFronts, DuplexBacks}
+
+// duplexFiles names each part inside the zip so they sort in print order
+var duplexFiles = map[DuplexPart]string{
+	DuplexInstructions: "0-instructions.pdf",
+	DuplexFronts:       "1-fronts.pdf",
+	DuplexBacks:        "2-backs.pdf",
+}
+
+// ValidDuplexPart reports whether part names a manual duplex file
+func ValidDuplexPart(part DuplexPart) bool {
+	_, ok := duplexFiles[part]
+	return ok
+}
+
+// RenderManualDuplex writes a zip with the instruction sheet, the front
+// pages and the back pages of a double-sided layout as separate PDFs, for
+// printers that cannot print both sides in one pass
+func (r *PDFRenderer) RenderManualDuplex(ctx context.Context, l *layout.Layout, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, part := range DuplexParts {
+		var buf bytes.Buffer
+		if err := r.RenderDuplexPart(ctx, l, part, &buf); err != nil {
+			return err
+		}
+		header := &zip.FileHeader{Name: duplexFiles[part], Method: zip.Deflate, Modified: l.CreatedAt}
+		if header.Modified.IsZero() {
+			header.Modified = time.Now()
+		}
+		f, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// RenderDuplexPart writes one file of a manual duplex export: the fronts,
+// the backs, or the instruction sheet
+func (r *PDFRenderer) RenderDuplexPart(ctx context.Context, l *layout.Layout, part DuplexPart, w io.Writer) error {
+	fronts, backs, err := l.ManualDuplex()
+	if err != nil {
+		return err
+	}
+	switch part {
+	case DuplexFronts:
+		return r.RenderPages(ctx, l, fronts, w)
+	case DuplexBacks:
+		return r.RenderPages(ctx, l, backs, w)
+	case DuplexInstructions:
+		return RenderDuplexInstructions(l, w)
+	}
+	return fmt.Errorf("invalid manual duplex part: %q", part)
+}
+
+// RenderDuplexInstructions writes a one-page sheet explaining how to print
+// the fronts, turn the stack for the layout's flip edge and reinsert it for
+// the backs
+func RenderDuplexInstructions(l *layout.Layout, w io.Writer) error {
+	page := l.Page
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: page.Width, Ht: page.Height},
+	})
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetCreator("Card Separator Generator", true)
+	pdf.SetTitle("Manual Duplex Instructions", true)
+	if !l.CreatedAt.IsZero() {
+		pdf.SetCreationDate(l.CreatedAt)
+		pdf.SetModificationDate(l.CreatedAt)
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Manual Duplex Printing", "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.MultiCell(0, 5, fmt.Sprintf(
+		"%d separators on %d sheets of %gx%gmm paper, %s-edge flip.",
+		l.SeparatorCount, l.SheetCount, page.Width, page.Height, l.Config.FlipEdge), "", "L", false)
+	pdf.Ln(4)
+
+	for i, step := range duplexSteps(l) {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(8, 6, fmt.Sprintf("%d.", i+1), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 6, step, "", "L", false)
+		pdf.Ln(2)
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.SetTextColor(75, 85, 99)
+	pdf.MultiCell(0, 4.5, "Tip: before a long run, draw an arrow pointing to the top edge on one blank sheet, "+
+		"print a single page on it and note which side and which way round the printer prints. "+
+		"If backs are shifted, print the duplex calibration sheet and save the offset in a printer profile.", "", "L", false)
+
+	return pdf.Output(w)
+}
+
+// duplexSteps are the numbered steps of the instruction sheet. They assume
+// the usual paper path: a face-down output tray, and an input tray that
+// feeds from the top of the stack and prints the side facing down.
+func duplexSteps(l *layout.Layout) []string {
+	turn := "Turn the whole stack over from left to right, like turning a book page, so the top edge of the fronts stays at the same end."
+	if l.Config.FlipEdge == layout.FlipShort {
+		turn = "Turn the whole stack over from top to bottom, like flipping a wall calendar, so the top edge of the fronts goes to the other end."
+	}
+	return []string{
+		fmt.Sprintf("Print 1-fronts.pdf (%d pages) at 100%% scale, single-sided. Do not use \"fit to page\".", l.SheetCount),
+		"Take the printed stack from the face-down output tray without changing its order. The last sheet is on top, printed side down.",
+		turn + " Sheet 1 is now on top with its blank side facing down.",
+		"Put the stack back in the main input tray as it is, blank side down. If your printer stacks face up, or prints the side facing up, load the stack so sheet 1 is fed first with its blank side towards the print side instead.",
+		fmt.Sprintf("Print 2-backs.pdf (%d pages) at 100%% scale, single-sided.", l.SheetCount),
+		"Check the first sheet before the rest finishes: its back should belong to the first sheet of fronts, with the tabs lining up. If not, cancel and adjust how the stack is turned or inserted.",
+	}
+}
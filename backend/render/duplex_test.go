@@ -0,0 +1,121 @@
+package render
+
+import (
+	"card-separator/database"
+	"card-separator/layout"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// paperSheet is one sheet in a simulated manual duplex run. down and up are
+// the sheet numbers printed on the faces currently facing down and up, 0
+// while blank.
+type paperSheet struct {
+	down, up int
+	front    int // Sheet number printed first, on the front
+}
+
+// paperStack is a stack of sheets, the last one on top
+type paperStack []*paperSheet
+
+// turnOver turns the whole stack over: the order reverses and every sheet
+// shows its other face
+func (s paperStack) turnOver() paperStack {
+	turned := make(paperStack, len(s))
+	for i, sheet := range s {
+		sheet.down, sheet.up = sheet.up, sheet.down
+		turned[len(s)-1-i] = sheet
+	}
+	return turned
+}
+
+// printRun feeds sheets from the top of in, prints pages on the side facing
+// down and drops them on a face-down output stack
+func printRun(t *testing.T, in paperStack, pages []layout.Page) paperStack {
+	t.Helper()
+	var out paperStack
+	for _, page := range pages {
+		if len(in) == 0 {
+			t.Fatalf("ran out of paper at sheet %d", page.Sheet)
+		}
+		sheet := in[len(in)-1]
+		in = in[:len(in)-1]
+		if sheet.down != 0 {
+			t.Fatalf("sheet %d %s printed over sheet %d", page.Sheet, page.Type, sheet.down)
+		}
+		sheet.down = page.Sheet
+		if page.Type == layout.PageFront {
+			sheet.front = page.Sheet
+		}
+		out = append(out, sheet)
+	}
+	return out
+}
+
+func TestManualDuplexSteps(t *testing.T) {
+	for _, edge := range []layout.FlipEdge{layout.FlipLong, layout.FlipShort} {
+		t.Run(string(edge), func(t *testing.T) {
+			cards := make([]database.Card, 30)
+			for i := range cards {
+				cards[i] = database.Card{CardSetID: fmt.Sprintf("C%d", i+1), CardName: fmt.Sprintf("Card %d", i+1)}
+			}
+			cfg := layout.DefaultPrintConfig()
+			cfg.DoubleSided = true
+			cfg.FlipEdge = edge
+			l, err := layout.Build(cards, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.SheetCount < 3 {
+				t.Fatalf("want at least 3 sheets, got %d", l.SheetCount)
+			}
+			fronts, backs, err := l.ManualDuplex()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The simulation below follows the steps as written
+			steps := duplexSteps(l)
+			for i, want := range map[int]string{
+				1: "face-down output tray without changing its order",
+				2: "Turn the whole stack over",
+				3: "blank side down",
+			} {
+				if !strings.Contains(steps[i], want) {
+					t.Errorf("step %d no longer says %q: %s", i+1, want, steps[i])
+				}
+			}
+			if !strings.Contains(steps[2], "Sheet 1 is now on top") {
+				t.Errorf("step 3 does not say which sheet is on top: %s", steps[2])
+			}
+
+			paper := make(paperStack, l.SheetCount)
+			for i := range paper {
+				paper[i] = &paperSheet{}
+			}
+			// 1. Print the fronts; 2. take the stack as it is
+			stack := printRun(t, paper, fronts)
+			if top := stack[len(stack)-1]; top.front != l.SheetCount {
+				t.Fatalf("sheet %d is on top of the output, want the last", top.front)
+			}
+			// 3. Turn the whole stack over; 4. put it back as it is
+			stack = stack.turnOver()
+			if top := stack[len(stack)-1]; top.front != 1 || top.down != 0 {
+				t.Fatalf("after turning, sheet %d is on top with %d facing down", top.front, top.down)
+			}
+			// 5. Print the backs
+			stack = printRun(t, stack, backs)
+
+			for _, sheet := range stack {
+				if sheet.down != sheet.front {
+					t.Errorf("sheet %d got the back of sheet %d", sheet.front, sheet.down)
+				}
+			}
+			// 6. The first back printed lands on the first sheet of fronts
+			if first := stack[0]; first.front != 1 {
+				t.Errorf("first back printed on sheet %d, the steps say sheet 1", first.front)
+			}
+		})
+	}
+}
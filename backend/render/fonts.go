@@ -0,0 +1,93 @@
+package render
+
+import (
+	"card-separator/fonts"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+// FontSource fetches uploaded font files by name, or a saved version of one
+// by its SHA-256 when version is set. services.FontService satisfies it.
+type FontSource interface {
+	GetFontVersion(ctx context.Context, name, version string) ([]byte, error)
+}
+
+// measureSize is the point size of the face labels are measured with.
+// Unhinted advances scale linearly, so one face serves every size.
+const measureSize = 100
+
+// ellipsis ends labels that are still too wide at the minimum font size
+const ellipsis = "…"
+
+// tabFont is the font a layout's tab labels are set in
+type tabFont struct {
+	name    string
+	data    []byte
+	font    *opentype.Font
+	measure font.Face
+}
+
+// loadTabFont loads the named font, a bundled one or an upload from src at
+// version if set. An empty name selects fonts.Default.
+func loadTabFont(ctx context.Context, src FontSource, name, version string) (*tabFont, error) {
+	if name == "" {
+		name = fonts.Default
+	}
+	data, ok := fonts.Bundled(name)
+	if !ok {
+		if src == nil {
+			return nil, fmt.Errorf("font %q is not bundled", name)
+		}
+		var err error
+		if data, err = src.GetFontVersion(ctx, name, version); err != nil {
+			return nil, fmt.Errorf("failed to load font %s: %w", name, err)
+		}
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font %s: %w", name, err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: measureSize, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face for %s: %w", name, err)
+	}
+	return &tabFont{name: name, data: data, font: f, measure: face}, nil
+}
+
+// width returns the advance width in mm of s set at size points
+func (t *tabFont) width(s string, size float64) float64 {
+	adv := font.MeasureString(t.measure, s)
+	return float64(adv) / 64 * size / measureSize * ptToMM
+}
+
+// fitLabel returns the label and point size to draw it in width mm. Labels
+// too wide at the style's font size are shrunk, down to MinFontSize, then
+// cut short with an ellipsis.
+func (t *tabFont) fitLabel(label string, width float64, style Style) (string, float64) {
+	size := style.FontSize
+	w := t.width(label, size)
+	if w <= width {
+		return label, size
+	}
+
+	// Round down to a tenth of a point so sizes are stable across formats
+	size = math.Max(math.Floor(size*width/w*10)/10, style.MinFontSize)
+	if t.width(label, size) <= width {
+		return label, size
+	}
+
+	runes := []rune(label)
+	for n := len(runes) - 1; n > 0; n-- {
+		short := strings.TrimRight(string(runes[:n]), " ") + ellipsis
+		if t.width(short, size) <= width {
+			return short, size
+		}
+	}
+	return "", size
+}
@@ -0,0 +1,230 @@
+package render
+
+import (
+	"card-separator/layout"
+	"math"
+)
+
+// rect is an axis-aligned box in millimetres
+type rect struct {
+	X, Y, W, H float64
+}
+
+// line is a straight segment in millimetres
+type line struct {
+	X1, Y1, X2, Y2 float64
+}
+
+// point is a position in millimetres
+type point struct {
+	X, Y float64
+}
+
+// labelPadding is the minimum gap in mm between the tab label and the trim
+// edge, matching the 4px tab padding in the browser
+const labelPadding = 1
+
+// cropMarkWeight is the stroke width in mm of crop marks
+const cropMarkWeight = 0.1
+
+// detailLineHeight is the height in mm of each boundary detail line
+const detailLineHeight = 4
+
+// faceGeometry splits a placed separator face into its drawn regions
+type faceGeometry struct {
+	Outline   rect    // Trim box
+	Tab       rect    // Tab strip along the top edge, extended into the bleed
+	Label     rect    // Area the tab label is centred and clipped in
+	ImageArea rect    // Background area below the tab, extended into the bleed
+	ImageBox  rect    // Box the art is fitted into
+	Details   rect    // Strip under the art for boundary detail lines, empty without details
+	QRCode    rect    // Square in the bottom-right of the art area, empty without a code
+	Shape     []point // Cut outline when the tab is staggered, nil when it is the trim box
+}
+
+// geometryFor computes the regions of a placement, matching the CSS layout
+// of the browser print view. Backgrounds run out into the bleed while the
+// label and art stay inside the safe area. With more than one tab position
+// the tab only covers its share of the top edge.
+func geometryFor(pl layout.Placement, l *layout.Layout, style Style) faceGeometry {
+	card, tabs := l.Card, l.Config.TabCount()
+	trim := boxRect(pl.Trim())
+	bleed := boxRect(pl.BleedBox)
+	safe := boxRect(pl.SafeBox)
+	tabBottom := trim.Y + card.TabHeight
+	b := trim.X - bleed.X
+
+	tab := rect{X: bleed.X, Y: bleed.Y, W: bleed.W, H: tabBottom - bleed.Y}
+	area := rect{X: bleed.X, Y: tabBottom, W: bleed.W, H: bleed.Y + bleed.H - tabBottom}
+	tabX, tabW := tabSpan(trim, pl.Tab, tabs)
+	var shape []point
+	if tabs > 1 {
+		tab = rect{X: tabX - b, Y: bleed.Y, W: tabW + 2*b, H: tabBottom - bleed.Y}
+		// The body's top edge beside the tab is cut too, so it gets bleed
+		area.Y, area.H = area.Y-b, area.H+b
+		shape = tabShape(trim, tabX, tabW, tabBottom)
+	}
+
+	inset := math.Max(safe.X-trim.X, labelPadding)
+	label := rect{X: tabX + inset, Y: trim.Y, W: tabW - 2*inset, H: card.TabHeight}
+
+	// Art is centred in the safe part of the image area, or fills the bleed
+	// when the centre size is 100%
+	artTop := math.Max(tabBottom, safe.Y)
+	artArea := rect{X: safe.X, Y: artTop, W: safe.W, H: safe.Y + safe.H - artTop}
+
+	// Boundary details take a strip along the bottom of the art area
+	var details rect
+	if n := len(pl.Details); n > 0 {
+		h := math.Min(float64(n)*detailLineHeight, artArea.H)
+		details = rect{X: artArea.X, Y: artArea.Y + artArea.H - h, W: artArea.W, H: h}
+		artArea.H -= h
+	}
+
+	// The QR code sits over the art in the bottom-right corner of the safe
+	// area, shrunk when the card is too small for the configured size
+	var qr rect
+	if pl.QRCode != "" {
+		side := math.Min(l.Config.QRCode.SideMM(), math.Min(artArea.W, artArea.H))
+		qr = rect{X: artArea.X + artArea.W - side, Y: artArea.Y + artArea.H - side, W: side, H: side}
+	}
+
+	box := area
+	if style.ImageCenterSize < 100 || bleed == trim || details.H > 0 {
+		scale := style.ImageCenterSize / 100
+		box = rect{W: artArea.W * scale, H: artArea.H * scale}
+		box.X = artArea.X + (artArea.W-box.W)/2
+		box.Y = artArea.Y + (artArea.H-box.H)/2
+	}
+
+	return faceGeometry{Outline: trim, Tab: tab, Label: label, ImageArea: area, ImageBox: box, Details: details, QRCode: qr, Shape: shape}
+}
+
+// detailLines splits the details strip into one row per line
+func (g faceGeometry) detailLines(n int) []rect {
+	rows := make([]rect, n)
+	for i := range rows {
+		rows[i] = rect{X: g.Details.X, Y: g.Details.Y + float64(i)*g.Details.H/float64(n), W: g.Details.W, H: g.Details.H / float64(n)}
+	}
+	return rows
+}
+
+// tabSpan returns where along the top edge of an upright trim box the tab
+// at position index is cut
+func tabSpan(trim rect, index, tabs int) (x, w float64) {
+	if tabs <= 1 {
+		return trim.X, trim.W
+	}
+	w = trim.W / float64(tabs)
+	return trim.X + w*float64(index), w
+}
+
+// cutPath returns the closed cut outline of a placement in page
+// coordinates, following the tab shape when tabs are staggered
+func cutPath(pl layout.Placement, card layout.CardDimensions, tabs int) []point {
+	angle, dx, dy := faceTransform(pl)
+	face := pl.Face()
+	trim := boxRect(face.Trim())
+
+	var pts []point
+	if tabs > 1 {
+		tabX, tabW := tabSpan(trim, face.Tab, tabs)
+		pts = tabShape(trim, tabX, tabW, trim.Y+card.TabHeight)
+	} else {
+		right, bottom := trim.X+trim.W, trim.Y+trim.H
+		pts = []point{{trim.X, trim.Y}, {right, trim.Y}, {right, bottom}, {trim.X, bottom}}
+	}
+
+	// Turn the upright face clockwise about its top-left corner, as the
+	// renderers' transforms do
+	for i, p := range pts {
+		vx, vy := p.X-pl.X, p.Y-pl.Y
+		switch angle {
+		case 90:
+			vx, vy = -vy, vx
+		case 270:
+			vx, vy = vy, -vx
+		}
+		pts[i] = point{pl.X + vx + dx, pl.Y + vy + dy}
+	}
+	return pts
+}
+
+// tabShape returns the clockwise outline of a card whose tab is cut between
+// tabX and tabX+tabW along the top edge
+func tabShape(trim rect, tabX, tabW, tabBottom float64) []point {
+	right, bottom := trim.X+trim.W, trim.Y+trim.H
+	pts := []point{
+		{tabX, trim.Y}, {tabX + tabW, trim.Y}, {tabX + tabW, tabBottom},
+		{right, tabBottom}, {right, bottom}, {trim.X, bottom},
+		{trim.X, tabBottom}, {tabX, tabBottom},
+	}
+
+	// Drop the corners that collapse when the tab sits at an edge
+	shape := make([]point, 0, len(pts))
+	for i, p := range pts {
+		next := pts[(i+1)%len(pts)]
+		if p == next {
+			continue
+		}
+		shape = append(shape, p)
+	}
+	return shape
+}
+
+// cropMarks returns the corner crop marks for a placement. Marks run along
+// the trim lines, starting outside the bleed and stopping short of the
+// neighbouring separator's trim line.
+func cropMarks(pl layout.Placement, cfg layout.PrintConfig) []line {
+	start := math.Max(cfg.Bleed, 1)
+	end := 2*cfg.Spacing() - 0.5
+
+	x1, y1 := pl.X, pl.Y
+	x2, y2 := pl.X+pl.Width, pl.Y+pl.Height
+	return []line{
+		// Top-left
+		{x1 - end, y1, x1 - start, y1},
+		{x1, y1 - end, x1, y1 - start},
+		// Top-right
+		{x2 + start, y1, x2 + end, y1},
+		{x2, y1 - end, x2, y1 - start},
+		// Bottom-left
+		{x1 - end, y2, x1 - start, y2},
+		{x1, y2 + start, x1, y2 + end},
+		// Bottom-right
+		{x2 + start, y2, x2 + end, y2},
+		{x2, y2 + start, x2, y2 + end},
+	}
+}
+
+// faceTransform returns how the upright face from Placement.Face is moved
+// onto the page: turned angle degrees clockwise about the placement's
+// top-left corner, then shifted by dx, dy
+func faceTransform(pl layout.Placement) (angle, dx, dy float64) {
+	switch pl.Rotation {
+	case 90:
+		return 90, pl.Width, 0
+	case 270:
+		return 270, 0, pl.Height
+	}
+	return 0, 0, 0
+}
+
+// boxRect converts a layout box to a rect
+func boxRect(b layout.Box) rect {
+	return rect{X: b.X, Y: b.Y, W: b.Width, H: b.Height}
+}
+
+// fitContain scales an image of the given pixel size into box, preserving
+// its aspect ratio (CSS object-fit: contain)
+func fitContain(imgW, imgH float64, box rect) rect {
+	if imgW <= 0 || imgH <= 0 {
+		return box
+	}
+	scale := box.W / imgW
+	if s := box.H / imgH; s < scale {
+		scale = s
+	}
+	w, h := imgW*scale, imgH*scale
+	return rect{X: box.X + (box.W-w)/2, Y: box.Y + (box.H-h)/2, W: w, H: h}
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"card-separator/layout"
+	"context"
+	"log"
+	"sync"
+)
+
+// ImageSource fetches card art at a named size. services.ImageService
+// satisfies it.
+type ImageSource interface {
+	GetImage(ctx context.Context, imageURL string, size string) ([]byte, error)
+}
+
+// maxImageFetches limits concurrent art requests per render
+const maxImageFetches = 8
+
+// fetchImages downloads the art for the given placements, keyed by image
+// URL. Failed images are logged and left out, matching the browser which
+// hides images that fail to load. Each URL fetched is a step of progress.
+func fetchImages(ctx context.Context, src ImageSource, cfg layout.PrintConfig, placements []layout.Placement, progress *tracker) map[string][]byte {
+	images := make(map[string][]byte)
+	if src == nil || !cfg.ShowImages {
+		return images
+	}
+
+	urls := imageURLs(placements)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxImageFetches)
+	for url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			defer progress.step()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			data, err := src.GetImage(ctx, url, cfg.ImageQuality)
+			if err != nil {
+				log.Printf("[RENDER] Warning: skipping image %s: %v", url, err)
+				return
+			}
+			mu.Lock()
+			images[url] = data
+			mu.Unlock()
+		}(url)
+	}
+	wg.Wait()
+
+	return images
+}
+
+// imageURLs returns the distinct art URLs of placements
+func imageURLs(placements []layout.Placement) map[string]struct{} {
+	urls := make(map[string]struct{})
+	for _, pl := range placements {
+		if pl.Card.CardImageURL != "" {
+			urls[pl.Card.CardImageURL] = struct{}{}
+		}
+	}
+	return urls
+}
+
+// allPlacements flattens the placements of pages
+func allPlacements(pages []layout.Page) []layout.Placement {
+	var placements []layout.Placement
+	for _, page := range pages {
+		placements = append(placements, page.Placements...)
+	}
+	return placements
+}
@@ -0,0 +1,312 @@
+package render
+
+import (
+	"bytes"
+	"card-separator/layout"
+	"context"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// pdfTabFamily is the family name the tab font is embedded under
+const pdfTabFamily = "tab"
+
+// PDFRenderer draws layouts as PDF documents at real millimetre sizes
+type PDFRenderer struct {
+	images ImageSource
+	fonts  FontSource
+	style  Style
+}
+
+// NewPDFRenderer creates a PDF renderer that pulls card art from images and
+// uploaded tab fonts from fonts
+func NewPDFRenderer(images ImageSource, fonts FontSource, style Style) *PDFRenderer {
+	return &PDFRenderer{images: images, fonts: fonts, style: style}
+}
+
+// pdfDoc carries the per-document state while drawing
+type pdfDoc struct {
+	*fpdf.Fpdf
+	layout     *layout.Layout
+	style      Style
+	font       *tabFont
+	images     map[string][]byte
+	registered map[string]*fpdf.ImageInfoType
+}
+
+// Render writes the layout as a multi-page PDF, one page per layout page
+func (r *PDFRenderer) Render(ctx context.Context, l *layout.Layout, w io.Writer) error {
+	return r.RenderProgress(ctx, l, w, nil)
+}
+
+// RenderProgress renders like Render, reporting progress as each image is
+// fetched, each page drawn and the document written
+func (r *PDFRenderer) RenderProgress(ctx context.Context, l *layout.Layout, w io.Writer, report Progress) error {
+	return r.renderPages(ctx, l, l.Pages, w, report)
+}
+
+// RenderPages writes only the given pages of the layout, in the order
+// given, fetching art for just those pages
+func (r *PDFRenderer) RenderPages(ctx context.Context, l *layout.Layout, pages []layout.Page, w io.Writer) error {
+	return r.renderPages(ctx, l, pages, w, nil)
+}
+
+// renderPages draws pages of the layout into one document
+func (r *PDFRenderer) renderPages(ctx context.Context, l *layout.Layout, pages []layout.Page, w io.Writer, report Progress) error {
+	tab, err := loadTabFont(ctx, r.fonts, l.Config.Font, l.FontVersion)
+	if err != nil {
+		return err
+	}
+
+	placements := allPlacements(pages)
+	steps := len(pages) + 1
+	if r.images != nil && l.Config.ShowImages {
+		steps += len(imageURLs(placements))
+	}
+	progress := newTracker(steps, report)
+
+	doc := r.newDoc(l, tab, fetchImages(ctx, r.images, l.Config, placements, progress))
+
+	for _, page := range pages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		doc.AddPage()
+		if page.Type == layout.PageFront {
+			doc.drawRegistrationMarks()
+		}
+		shifted := page.Type == layout.PageBack && l.BackOffset != (layout.Offset{})
+		if shifted {
+			// Compensate for the printer's duplex misregistration
+			doc.TransformBegin()
+			doc.TransformTranslate(l.BackOffset.X, l.BackOffset.Y)
+		}
+		for _, pl := range page.Placements {
+			doc.drawFace(pl)
+		}
+		if l.Config.CropMarks {
+			doc.drawCropMarks(page.Placements)
+		}
+		doc.setPageBoxes(page)
+		if shifted {
+			doc.TransformEnd()
+		}
+		progress.step()
+	}
+
+	if err := doc.Output(w); err != nil {
+		return err
+	}
+	progress.step()
+	return nil
+}
+
+// newDoc creates an empty document sized to the layout's page, with the tab
+// font embedded
+func (r *PDFRenderer) newDoc(l *layout.Layout, tab *tabFont, images map[string][]byte) *pdfDoc {
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: l.Page.Width, Ht: l.Page.Height},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetCreator("Card Separator Generator", true)
+	pdf.SetTitle("Card Separators", true)
+	pdf.SetCatalogSort(true)
+	if !l.CreatedAt.IsZero() {
+		pdf.SetCreationDate(l.CreatedAt)
+		pdf.SetModificationDate(l.CreatedAt)
+	}
+	pdf.AddUTF8FontFromBytes(pdfTabFamily, "", tab.data)
+
+	return &pdfDoc{
+		Fpdf:       pdf,
+		layout:     l,
+		style:      r.style,
+		font:       tab,
+		images:     images,
+		registered: make(map[string]*fpdf.ImageInfoType),
+	}
+}
+
+// drawFace draws one separator face: image area, art, tab and border.
+// Rotated faces are drawn upright and turned into place.
+func (d *pdfDoc) drawFace(pl layout.Placement) {
+	if angle, dx, dy := faceTransform(pl); angle != 0 {
+		d.TransformBegin()
+		d.TransformTranslate(dx, dy)
+		d.TransformRotate(-angle, pl.X, pl.Y) // fpdf turns anticlockwise
+		defer d.TransformEnd()
+		pl = pl.Face()
+	}
+
+	g := geometryFor(pl, d.layout, d.style)
+
+	if d.layout.Config.ShowImages && pl.Card.CardImageURL != "" {
+		bg := mustColor(d.style.ImageBackground)
+		d.SetFillColor(bg.R, bg.G, bg.B)
+		d.Rect(g.ImageArea.X, g.ImageArea.Y, g.ImageArea.W, g.ImageArea.H, "F")
+
+		if info := d.image(pl.Card.CardImageURL); info != nil {
+			fit := fitContain(info.Width(), info.Height(), g.ImageBox)
+			d.ImageOptions(pl.Card.CardImageURL, fit.X, fit.Y, fit.W, fit.H, false, fpdf.ImageOptions{}, 0, "")
+		}
+	}
+
+	for i, row := range g.detailLines(len(pl.Details)) {
+		d.drawText(row, pl.Details[i], d.style.detail())
+	}
+
+	if modules := faceQRModules(pl, g); modules != nil {
+		d.drawQRCode(g.QRCode, modules)
+	}
+
+	d.drawTab(g, pl.Label)
+	d.drawOutline(g)
+}
+
+// drawQRCode draws a QR code's background and dark modules
+func (d *pdfDoc) drawQRCode(box rect, modules []rect) {
+	bg, fg := mustColor(qrBackground), mustColor(qrModuleColor)
+	d.SetFillColor(bg.R, bg.G, bg.B)
+	d.Rect(box.X, box.Y, box.W, box.H, "F")
+	d.SetFillColor(fg.R, fg.G, fg.B)
+	for _, m := range modules {
+		d.Rect(m.X, m.Y, m.W, m.H, "F")
+	}
+}
+
+// drawTab fills the tab strip and centres the label, shrunk or cut short to
+// fit and clipped to its area
+func (d *pdfDoc) drawTab(g faceGeometry, label string) {
+	fill := mustColor(d.style.TabColor)
+	d.SetFillColor(fill.R, fill.G, fill.B)
+	d.Rect(g.Tab.X, g.Tab.Y, g.Tab.W, g.Tab.H, "F")
+	d.drawText(g.Label, label, d.style)
+}
+
+// drawText centres text in area in the style's text colour, shrunk or cut
+// short to fit and clipped to the area
+func (d *pdfDoc) drawText(area rect, text string, style Style) {
+	c := mustColor(style.TextColor)
+	d.SetTextColor(c.R, c.G, c.B)
+	text, size := d.font.fitLabel(text, area.W, style)
+	d.SetFont(pdfTabFamily, "", size)
+
+	d.ClipRect(area.X, area.Y, area.W, area.H, false)
+	d.SetXY(area.X, area.Y)
+	d.CellFormat(area.W, area.H, text, "", 0, "CM", false, 0, "")
+	d.ClipEnd()
+}
+
+// drawCropMarks draws corner crop marks around every placement
+func (d *pdfDoc) drawCropMarks(placements []layout.Placement) {
+	d.SetDrawColor(0, 0, 0)
+	d.SetLineWidth(cropMarkWeight)
+	for _, pl := range placements {
+		for _, m := range cropMarks(pl, d.layout.Config) {
+			d.Line(m.X1, m.Y1, m.X2, m.Y2)
+		}
+	}
+}
+
+// drawRegistrationMarks draws the layout's print-and-cut registration marks
+func (d *pdfDoc) drawRegistrationMarks() {
+	d.SetFillColor(0, 0, 0)
+	for _, m := range d.layout.Registration {
+		d.Rect(m.X, m.Y, m.Width, m.Height, "F")
+	}
+}
+
+// setPageBoxes records the page's trim and bleed boxes, shifted by the back
+// offset on back pages. PDF page boxes use a bottom-left origin.
+func (d *pdfDoc) setPageBoxes(page layout.Page) {
+	if d.layout.Config.Bleed == 0 && !d.layout.Config.CropMarks {
+		return
+	}
+
+	var offset layout.Offset
+	if page.Type == layout.PageBack {
+		offset = d.layout.BackOffset
+	}
+	for name, box := range map[string]layout.Box{"trim": page.TrimBox, "bleed": page.BleedBox} {
+		d.SetPageBox(name,
+			box.X+offset.X,
+			d.layout.Page.Height-(box.Y+offset.Y)-box.Height,
+			box.Width, box.Height)
+	}
+}
+
+// drawOutline draws the separator border, or a dashed cut line, following
+// the tab shape when tabs are staggered
+func (d *pdfDoc) drawOutline(g faceGeometry) {
+	if d.layout.Config.ShowCutLines {
+		c := mustColor(d.style.CutLineColor)
+		d.SetDrawColor(c.R, c.G, c.B)
+		d.SetLineWidth(0.26)
+		d.SetDashPattern([]float64{1, 1}, 0)
+	} else {
+		if d.style.BorderWidth == 0 {
+			return
+		}
+		c := mustColor(d.style.BorderColor)
+		d.SetDrawColor(c.R, c.G, c.B)
+		d.SetLineWidth(d.style.BorderWidth)
+	}
+	if g.Shape != nil {
+		pts := make([]fpdf.PointType, len(g.Shape))
+		for i, p := range g.Shape {
+			pts[i] = fpdf.PointType{X: p.X, Y: p.Y}
+		}
+		d.Polygon(pts, "D")
+	} else {
+		d.Rect(g.Outline.X, g.Outline.Y, g.Outline.W, g.Outline.H, "D")
+	}
+	d.SetDashPattern([]float64{}, 0)
+}
+
+// image registers the art for url with the document on first use. Images
+// that cannot be embedded are skipped.
+func (d *pdfDoc) image(url string) *fpdf.ImageInfoType {
+	if info, ok := d.registered[url]; ok {
+		return info
+	}
+
+	data, ok := d.images[url]
+	if !ok {
+		return nil
+	}
+
+	imageType := pdfImageType(data)
+	if imageType == "" {
+		log.Printf("[RENDER] Warning: unsupported image format for %s", url)
+		d.registered[url] = nil
+		return nil
+	}
+
+	info := d.RegisterImageOptionsReader(url, fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if d.Err() {
+		log.Printf("[RENDER] Warning: failed to embed image %s: %v", url, d.Fpdf.Error())
+		d.ClearError()
+		info = nil
+	}
+	d.registered[url] = info
+	return info
+}
+
+// pdfImageType maps sniffed image data onto an fpdf image type
+func pdfImageType(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "JPG"
+	case "image/png":
+		return "PNG"
+	case "image/gif":
+		return "GIF"
+	}
+	return ""
+}
@@ -0,0 +1,372 @@
+package render
+
+import (
+	"bytes"
+	"card-separator/layout"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"math"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// AllowedDPIs are the resolutions pages can be rasterised at
+var AllowedDPIs = []int{150, 300, 600}
+
+// PreviewDPI is the resolution used for whole-layout preview sheets
+const PreviewDPI = 36
+
+// ValidDPI reports whether dpi is one of AllowedDPIs
+func ValidDPI(dpi int) bool {
+	for _, d := range AllowedDPIs {
+		if d == dpi {
+			return true
+		}
+	}
+	return false
+}
+
+// PNGRenderer rasterises layout pages to PNG images
+type PNGRenderer struct {
+	images ImageSource
+	fonts  FontSource
+	style  Style
+}
+
+// NewPNGRenderer creates a PNG renderer that pulls card art from images and
+// uploaded tab fonts from fonts
+func NewPNGRenderer(images ImageSource, fonts FontSource, style Style) *PNGRenderer {
+	return &PNGRenderer{images: images, fonts: fonts, style: style}
+}
+
+// raster carries the per-image state while drawing
+type raster struct {
+	canvas *image.NRGBA
+	origin layout.Offset // Page position in mm of the canvas' top-left corner
+	layout *layout.Layout
+	style  Style
+	dpi    float64
+	font   *tabFont
+	faces  map[float64]font.Face // Tab font faces by point size
+	art    map[string]image.Image
+}
+
+// RenderPage writes page n (1-indexed) of the layout as a PNG at dpi
+func (r *PNGRenderer) RenderPage(ctx context.Context, l *layout.Layout, n int, dpi int, w io.Writer) error {
+	page, ok := l.PageByNumber(n)
+	if !ok {
+		return fmt.Errorf("page %d out of range (1-%d)", n, len(l.Pages))
+	}
+
+	tab, err := loadTabFont(ctx, r.fonts, l.Config.Font, l.FontVersion)
+	if err != nil {
+		return err
+	}
+	img, err := r.rasterisePage(l, page, float64(dpi), tab, decodeImages(fetchImages(ctx, r.images, l.Config, page.Placements, nil)))
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// RenderPreview writes every page of the layout side by side at PreviewDPI
+// as a single contact sheet, with front and back pages of a sheet adjacent
+func (r *PNGRenderer) RenderPreview(ctx context.Context, l *layout.Layout, w io.Writer) error {
+	const gap = 8 // px between pages
+	const columns = 4
+
+	tab, err := loadTabFont(ctx, r.fonts, l.Config.Font, l.FontVersion)
+	if err != nil {
+		return err
+	}
+	art := decodeImages(fetchImages(ctx, r.images, l.Config, allPlacements(l.Pages), nil))
+	pageW := mmToPx(l.Page.Width, PreviewDPI)
+	pageH := mmToPx(l.Page.Height, PreviewDPI)
+
+	cols := columns
+	if len(l.Pages) < cols {
+		cols = len(l.Pages)
+	}
+	rows := (len(l.Pages) + columns - 1) / columns
+	sheet := imaging.New(gap+cols*(pageW+gap), gap+rows*(pageH+gap), color.NRGBA{0xE5, 0xE7, 0xEB, 0xFF})
+
+	for i := range l.Pages {
+		img, err := r.rasterisePage(l, &l.Pages[i], PreviewDPI, tab, art)
+		if err != nil {
+			return err
+		}
+		at := image.Pt(gap+(i%columns)*(pageW+gap), gap+(i/columns)*(pageH+gap))
+		draw.Draw(sheet, img.Bounds().Add(at), img, image.Point{}, draw.Src)
+	}
+
+	return png.Encode(w, sheet)
+}
+
+// rasterisePage draws one page onto a white canvas
+func (r *PNGRenderer) rasterisePage(l *layout.Layout, page *layout.Page, dpi float64, tab *tabFont, art map[string]image.Image) (*image.NRGBA, error) {
+	rs := &raster{
+		canvas: imaging.New(mmToPx(l.Page.Width, dpi), mmToPx(l.Page.Height, dpi), color.White),
+		layout: l,
+		style:  r.style,
+		dpi:    dpi,
+		font:   tab,
+		faces:  make(map[float64]font.Face),
+		art:    art,
+	}
+	defer rs.closeFaces()
+
+	for _, pl := range page.Placements {
+		rs.drawFace(pl)
+	}
+	if l.Config.CropMarks {
+		for _, pl := range page.Placements {
+			for _, m := range cropMarks(pl, l.Config) {
+				rs.drawLine(m, cropMarkWeight, "#000000")
+			}
+		}
+	}
+	if page.Type == layout.PageFront {
+		for _, m := range l.Registration {
+			rs.fill(boxRect(m), "#000000")
+		}
+	}
+	return rs.canvas, nil
+}
+
+// drawFace draws one separator face: image area, art, tab and border
+func (rs *raster) drawFace(pl layout.Placement) {
+	if pl.Rotation != 0 {
+		rs.drawRotatedFace(pl)
+		return
+	}
+	g := geometryFor(pl, rs.layout, rs.style)
+
+	if rs.layout.Config.ShowImages && pl.Card.CardImageURL != "" {
+		rs.fill(g.ImageArea, rs.style.ImageBackground)
+		if img, ok := rs.art[pl.Card.CardImageURL]; ok {
+			b := img.Bounds()
+			fit := rs.pxRect(fitContain(float64(b.Dx()), float64(b.Dy()), g.ImageBox))
+			if fit.Dx() > 0 && fit.Dy() > 0 {
+				resized := imaging.Resize(img, fit.Dx(), fit.Dy(), imaging.Lanczos)
+				draw.Draw(rs.canvas, fit, resized, image.Point{}, draw.Over)
+			}
+		}
+	}
+
+	for i, row := range g.detailLines(len(pl.Details)) {
+		rs.drawText(row, pl.Details[i], rs.style.detail())
+	}
+
+	if modules := faceQRModules(pl, g); modules != nil {
+		rs.fill(g.QRCode, qrBackground)
+		for _, m := range modules {
+			rs.fill(m, qrModuleColor)
+		}
+	}
+
+	rs.fill(g.Tab, rs.style.TabColor)
+	rs.drawText(g.Label, pl.Label, rs.style)
+	rs.drawOutline(g)
+}
+
+// drawRotatedFace draws the upright face on its own canvas, turns it and
+// composites it over the placement's bleed box
+func (rs *raster) drawRotatedFace(pl layout.Placement) {
+	face := pl.Face()
+	bleed := boxRect(face.BleedBox)
+	sub := *rs
+	sub.origin = layout.Offset{X: bleed.X, Y: bleed.Y}
+	sub.canvas = imaging.New(mmToPx(bleed.W, rs.dpi), mmToPx(bleed.H, rs.dpi), color.Transparent)
+	sub.drawFace(face)
+
+	var turned *image.NRGBA
+	if pl.Rotation == 90 {
+		turned = imaging.Rotate270(sub.canvas) // imaging turns anticlockwise
+	} else {
+		turned = imaging.Rotate90(sub.canvas)
+	}
+	at := rs.pxRect(boxRect(pl.BleedBox)).Min
+	draw.Draw(rs.canvas, turned.Bounds().Add(at), turned, image.Point{}, draw.Over)
+}
+
+// drawText centres text in area in the style's text colour, shrunk or cut
+// short to fit and clipped to the area
+func (rs *raster) drawText(area rect, label string, style Style) {
+	bounds := rs.pxRect(area)
+	dst, ok := rs.canvas.SubImage(bounds).(*image.NRGBA)
+	if !ok {
+		return
+	}
+	label, size := rs.font.fitLabel(label, area.W, style)
+	face, err := rs.face(size)
+	if err != nil {
+		log.Printf("[RENDER] Warning: skipping label %q: %v", label, err)
+		return
+	}
+
+	c := mustColor(style.TextColor)
+	d := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(color.NRGBA{uint8(c.R), uint8(c.G), uint8(c.B), 0xFF}),
+		Face: face,
+	}
+	metrics := face.Metrics()
+	width := d.MeasureString(label)
+	x := fixed.I(bounds.Min.X+bounds.Dx()/2) - width/2
+	y := fixed.I(bounds.Min.Y+bounds.Dy()/2) + (metrics.Ascent-metrics.Descent)/2
+	d.Dot = fixed.Point26_6{X: x, Y: y}
+	d.DrawString(label)
+}
+
+// face returns the tab font face at size points, created on first use
+func (rs *raster) face(size float64) (font.Face, error) {
+	if face, ok := rs.faces[size]; ok {
+		return face, nil
+	}
+	face, err := opentype.NewFace(rs.font.font, &opentype.FaceOptions{Size: size, DPI: rs.dpi, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tab font face: %w", err)
+	}
+	rs.faces[size] = face
+	return face, nil
+}
+
+// closeFaces releases the faces created while drawing
+func (rs *raster) closeFaces() {
+	for _, face := range rs.faces {
+		face.Close()
+	}
+}
+
+// drawOutline draws the separator border, or a dashed cut line, following
+// the tab shape when tabs are staggered
+func (rs *raster) drawOutline(g faceGeometry) {
+	stroke := func(width float64, hex string, dash float64) {
+		if g.Shape != nil {
+			rs.strokeShape(g.Shape, width, hex, dash)
+		} else {
+			rs.strokeRect(g.Outline, width, hex, dash)
+		}
+	}
+	if rs.layout.Config.ShowCutLines {
+		stroke(0.26, rs.style.CutLineColor, 1)
+		return
+	}
+	if rs.style.BorderWidth > 0 {
+		stroke(rs.style.BorderWidth, rs.style.BorderColor, 0)
+	}
+}
+
+// strokeShape draws the axis-aligned edges of a closed outline, dashed like
+// strokeRect when dash is non-zero
+func (rs *raster) strokeShape(pts []point, width float64, hex string, dash float64) {
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		if dash == 0 {
+			rs.drawLine(line{p.X, p.Y, q.X, q.Y}, width, hex)
+			continue
+		}
+		length := math.Hypot(q.X-p.X, q.Y-p.Y)
+		ux, uy := (q.X-p.X)/length, (q.Y-p.Y)/length
+		for offset := 0.0; offset < length; offset += 2 * dash {
+			seg := math.Min(dash, length-offset)
+			rs.drawLine(line{
+				p.X + ux*offset, p.Y + uy*offset,
+				p.X + ux*(offset+seg), p.Y + uy*(offset+seg),
+			}, width, hex)
+		}
+	}
+}
+
+// strokeRect draws a rectangle outline inside r. A non-zero dash draws
+// dashes of that length in millimetres.
+func (rs *raster) strokeRect(r rect, width float64, hex string, dash float64) {
+	lw := rs.minWidth(width)
+	edges := []rect{
+		{X: r.X, Y: r.Y, W: r.W, H: lw},
+		{X: r.X, Y: r.Y + r.H - lw, W: r.W, H: lw},
+		{X: r.X, Y: r.Y, W: lw, H: r.H},
+		{X: r.X + r.W - lw, Y: r.Y, W: lw, H: r.H},
+	}
+	for _, e := range edges {
+		if dash == 0 {
+			rs.fill(e, hex)
+			continue
+		}
+		horizontal := e.W > e.H
+		length := e.H
+		if horizontal {
+			length = e.W
+		}
+		for offset := 0.0; offset < length; offset += 2 * dash {
+			seg := math.Min(dash, length-offset)
+			if horizontal {
+				rs.fill(rect{X: e.X + offset, Y: e.Y, W: seg, H: e.H}, hex)
+			} else {
+				rs.fill(rect{X: e.X, Y: e.Y + offset, W: e.W, H: seg}, hex)
+			}
+		}
+	}
+}
+
+// drawLine draws a horizontal or vertical line centred on the segment
+func (rs *raster) drawLine(l line, width float64, hex string) {
+	lw := rs.minWidth(width)
+	x, y := math.Min(l.X1, l.X2), math.Min(l.Y1, l.Y2)
+	w, h := math.Abs(l.X2-l.X1), math.Abs(l.Y2-l.Y1)
+	if w == 0 {
+		rs.fill(rect{X: x - lw/2, Y: y, W: lw, H: h}, hex)
+	} else {
+		rs.fill(rect{X: x, Y: y - lw/2, W: w, H: lw}, hex)
+	}
+}
+
+// minWidth returns width in mm, raised to at least one pixel
+func (rs *raster) minWidth(width float64) float64 {
+	if px := 25.4 / rs.dpi; width < px {
+		return px
+	}
+	return width
+}
+
+// fill paints a millimetre rect with a solid colour
+func (rs *raster) fill(r rect, hex string) {
+	c := mustColor(hex)
+	draw.Draw(rs.canvas, rs.pxRect(r), image.NewUniform(color.NRGBA{uint8(c.R), uint8(c.G), uint8(c.B), 0xFF}), image.Point{}, draw.Src)
+}
+
+// pxRect converts a millimetre rect to pixels at the raster's DPI
+func (rs *raster) pxRect(r rect) image.Rectangle {
+	x, y := r.X-rs.origin.X, r.Y-rs.origin.Y
+	return image.Rect(mmToPx(x, rs.dpi), mmToPx(y, rs.dpi), mmToPx(x+r.W, rs.dpi), mmToPx(y+r.H, rs.dpi))
+}
+
+// mmToPx converts millimetres to whole pixels at dpi
+func mmToPx(mm, dpi float64) int {
+	return int(math.Round(mm * dpi / 25.4))
+}
+
+// decodeImages decodes fetched art, skipping images that fail to decode
+func decodeImages(data map[string][]byte) map[string]image.Image {
+	images := make(map[string]image.Image, len(data))
+	for url, b := range data {
+		img, _, err := image.Decode(bytes.NewReader(b))
+		if err != nil {
+			log.Printf("[RENDER] Warning: failed to decode image %s: %v", url, err)
+			continue
+		}
+		images[url] = img
+	}
+	return images
+}
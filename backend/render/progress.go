@@ -0,0 +1,38 @@
+package render
+
+import "sync"
+
+// Progress receives how many of a render's steps are done out of total.
+// It may be called from several goroutines, but never concurrently.
+type Progress func(done, total int)
+
+// tracker counts completed render steps and reports them to a Progress. A
+// nil tracker ignores steps.
+type tracker struct {
+	mu     sync.Mutex
+	done   int
+	total  int
+	report Progress
+}
+
+// newTracker returns a tracker for total steps, or nil when report is nil
+func newTracker(total int, report Progress) *tracker {
+	if report == nil {
+		return nil
+	}
+	report(0, total)
+	return &tracker{total: total, report: report}
+}
+
+// step marks one step done
+func (t *tracker) step() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done < t.total {
+		t.done++
+	}
+	t.report(t.done, t.total)
+}
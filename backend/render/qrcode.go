@@ -0,0 +1,61 @@
+package render
+
+import (
+	"card-separator/layout"
+	"log"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrBackground is the colour behind QR codes, so they scan over dark art
+const qrBackground = "#FFFFFF"
+
+// qrModuleColor is the colour of the dark QR modules
+const qrModuleColor = "#000000"
+
+// qrModules encodes content as a QR code with medium error correction and
+// returns its dark modules scaled into box, merged into one rect per run
+// along each row. The encoder's four-module quiet zone is part of box.
+func qrModules(content string, box rect) ([]rect, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := code.Bitmap()
+	module := box.W / float64(len(bitmap))
+
+	var runs []rect
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			runs = append(runs, rect{
+				X: box.X + float64(start)*module,
+				Y: box.Y + float64(y)*module,
+				W: float64(x-start) * module,
+				H: module,
+			})
+		}
+	}
+	return runs, nil
+}
+
+// faceQRModules returns the dark modules of a face's QR code in its
+// geometry, or nil when it has none. Codes that cannot be encoded are
+// logged and left out.
+func faceQRModules(pl layout.Placement, g faceGeometry) []rect {
+	if pl.QRCode == "" || g.QRCode.W <= 0 {
+		return nil
+	}
+	runs, err := qrModules(pl.QRCode, g.QRCode)
+	if err != nil {
+		log.Printf("[RENDER] Warning: skipping QR code for %s: %v", pl.Card.CardSetID, err)
+		return nil
+	}
+	return runs
+}
@@ -0,0 +1,28 @@
+package render
+
+import (
+	"card-separator/layout"
+	"strings"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+func TestQRCapacityModuleSize(t *testing.T) {
+	for size := float64(layout.MinQRSize); size <= layout.MaxQRSize; size += 0.5 {
+		q := layout.QRCode{Enabled: true, Size: size}
+		capacity := q.Capacity()
+		if capacity == 0 {
+			t.Errorf("%gmm code has no capacity", size)
+			continue
+		}
+		// Lower case letters force byte mode, the least dense
+		code, err := qrcode.New(strings.Repeat("a", capacity), qrcode.Medium)
+		if err != nil {
+			t.Fatalf("%gmm: %v", size, err)
+		}
+		if module := size / float64(len(code.Bitmap())); module < layout.MinQRModule {
+			t.Errorf("%gmm code of %d bytes has %.3fmm modules, want at least %gmm", size, capacity, module, layout.MinQRModule)
+		}
+	}
+}
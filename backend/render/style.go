@@ -0,0 +1,105 @@
+// Package render draws separator layouts into printable output formats.
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Style holds the visual settings used when drawing separators. Defaults
+// mirror the tab and visual config in web/src/routes/+page.svelte.
+type Style struct {
+	TabColor        string  `json:"tab_color"`
+	TextColor       string  `json:"text_color"`
+	FontSize        float64 `json:"font_size"`     // Points
+	MinFontSize     float64 `json:"min_font_size"` // Points long labels may shrink to before being cut short
+	BorderColor     string  `json:"border_color"`
+	BorderWidth     float64 `json:"border_width"` // Millimetres
+	CutLineColor    string  `json:"cut_line_color"`
+	ImageBackground string  `json:"image_background"`
+	ImageCenterSize float64 `json:"image_center_size"` // Percent of the image area
+	DetailColor     string  `json:"detail_color"`      // Boundary detail lines
+}
+
+// DefaultStyle returns the style used by the browser print view
+func DefaultStyle() Style {
+	return Style{
+		TabColor:        "#B91C1C",
+		TextColor:       "#FFFFFF",
+		FontSize:        9,
+		MinFontSize:     6,
+		BorderColor:     "#000000",
+		BorderWidth:     0.26,
+		CutLineColor:    "#999999",
+		ImageBackground: "#F3F4F6",
+		ImageCenterSize: 80,
+		DetailColor:     "#374151",
+	}
+}
+
+// detailFontSize is the point size boundary detail lines are set at
+const detailFontSize = 7
+
+// detail returns the style boundary detail lines are drawn in: the tab font
+// at a smaller size, in the detail colour
+func (s Style) detail() Style {
+	d := s
+	d.FontSize = detailFontSize
+	d.MinFontSize = math.Min(s.MinFontSize, detailFontSize)
+	d.TextColor = s.DetailColor
+	return d
+}
+
+// rgb is a colour with 0-255 components
+type rgb struct {
+	R, G, B int
+}
+
+// parseHexColor parses #RGB or #RRGGBB colours
+func parseHexColor(s string) (rgb, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return rgb{}, fmt.Errorf("invalid colour: %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgb{}, fmt.Errorf("invalid colour: %q", s)
+	}
+	return rgb{R: int(v >> 16 & 0xFF), G: int(v >> 8 & 0xFF), B: int(v & 0xFF)}, nil
+}
+
+// mustColor parses a colour, falling back to black for invalid input
+func mustColor(s string) rgb {
+	c, err := parseHexColor(s)
+	if err != nil {
+		return rgb{}
+	}
+	return c
+}
+
+// Validate checks the style's colours and sizes
+func (s Style) Validate() error {
+	for _, c := range []string{s.TabColor, s.TextColor, s.BorderColor, s.CutLineColor, s.ImageBackground, s.DetailColor} {
+		if _, err := parseHexColor(c); err != nil {
+			return err
+		}
+	}
+	if s.FontSize <= 0 {
+		return fmt.Errorf("font_size must be positive")
+	}
+	if s.MinFontSize <= 0 || s.MinFontSize > s.FontSize {
+		return fmt.Errorf("min_font_size must be positive and no larger than font_size")
+	}
+	if s.BorderWidth < 0 {
+		return fmt.Errorf("border_width must not be negative")
+	}
+	if s.ImageCenterSize <= 0 || s.ImageCenterSize > 100 {
+		return fmt.Errorf("image_center_size must be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package render
+
+import (
+	"bytes"
+	"card-separator/layout"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ptToMM converts font points to millimetres
+const ptToMM = 25.4 / 72
+
+// SVGRenderer draws single layout pages as standalone SVG documents. Output
+// is deterministic for a given layout and image set, so pages can be
+// compared against golden files.
+type SVGRenderer struct {
+	images ImageSource
+	fonts  FontSource
+	style  Style
+}
+
+// NewSVGRenderer creates an SVG renderer that pulls card art from images and
+// uploaded tab fonts from fonts
+func NewSVGRenderer(images ImageSource, fonts FontSource, style Style) *SVGRenderer {
+	return &SVGRenderer{images: images, fonts: fonts, style: style}
+}
+
+// RenderPage writes page n (1-indexed) of the layout as an SVG document in
+// millimetre user units, with card art and the tab font embedded as data URIs
+func (r *SVGRenderer) RenderPage(ctx context.Context, l *layout.Layout, n int, w io.Writer) error {
+	page, ok := l.PageByNumber(n)
+	if !ok {
+		return fmt.Errorf("page %d out of range (1-%d)", n, len(l.Pages))
+	}
+	tab, err := loadTabFont(ctx, r.fonts, l.Config.Font, l.FontVersion)
+	if err != nil {
+		return err
+	}
+	images := fetchImages(ctx, r.images, l.Config, page.Placements, nil)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%smm\" height=\"%smm\" viewBox=\"0 0 %s %s\">\n",
+		num(l.Page.Width), num(l.Page.Height), num(l.Page.Width), num(l.Page.Height))
+	fmt.Fprintf(&buf, "<title>Page %d (%s)</title>\n", page.Number, page.Type)
+	fmt.Fprintf(&buf, "<defs><style>@font-face{font-family:\"%s\";src:url(data:font/ttf;base64,%s)}</style></defs>\n",
+		escape(tab.name), base64.StdEncoding.EncodeToString(tab.data))
+
+	for _, pl := range page.Placements {
+		r.writeFace(&buf, l, tab, pl, images)
+	}
+	if l.Config.CropMarks {
+		writeCropMarks(&buf, l, page.Placements)
+	}
+	if page.Type == layout.PageFront {
+		writeRegistrationMarks(&buf, l)
+	}
+
+	buf.WriteString("</svg>\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeFace writes one separator face as a group of image, tab, label and
+// border elements. Rotated faces are written upright and transformed.
+func (r *SVGRenderer) writeFace(buf *bytes.Buffer, l *layout.Layout, tab *tabFont, pl layout.Placement, images map[string][]byte) {
+	id := fmt.Sprintf("slot-%d", pl.Slot)
+	transform := ""
+	if angle, dx, dy := faceTransform(pl); angle != 0 {
+		transform = fmt.Sprintf(" transform=\"translate(%s %s) rotate(%s %s %s)\"", num(dx), num(dy), num(angle), num(pl.X), num(pl.Y))
+		pl = pl.Face()
+	}
+	g := geometryFor(pl, l, r.style)
+
+	fmt.Fprintf(buf, "<g id=\"%s\" data-separator=\"%d\" data-card=\"%s\"%s>\n", id, pl.Separator, escape(pl.Card.CardSetID), transform)
+
+	if l.Config.ShowImages && pl.Card.CardImageURL != "" {
+		fmt.Fprintf(buf, "  <rect %s fill=\"%s\"/>\n", rectAttrs(g.ImageArea), r.style.ImageBackground)
+		if data, ok := images[pl.Card.CardImageURL]; ok {
+			fmt.Fprintf(buf, "  <image %s preserveAspectRatio=\"xMidYMid meet\" href=\"data:%s;base64,%s\"/>\n",
+				rectAttrs(g.ImageBox), http.DetectContentType(data), base64.StdEncoding.EncodeToString(data))
+		}
+	}
+
+	for i, row := range g.detailLines(len(pl.Details)) {
+		writeText(buf, tab, fmt.Sprintf("%s-detail-%d", id, i), row, pl.Details[i], r.style.detail())
+	}
+
+	if modules := faceQRModules(pl, g); modules != nil {
+		writeQRCode(buf, g.QRCode, modules)
+	}
+
+	fmt.Fprintf(buf, "  <rect %s fill=\"%s\"/>\n", rectAttrs(g.Tab), r.style.TabColor)
+	writeText(buf, tab, id+"-label", g.Label, pl.Label, r.style)
+
+	r.writeOutline(buf, l, g)
+	buf.WriteString("</g>\n")
+}
+
+// writeText writes text centred in area in the style's text colour, shrunk
+// or cut short to fit and clipped to the area by the clip path clipID
+func writeText(buf *bytes.Buffer, tab *tabFont, clipID string, area rect, text string, style Style) {
+	text, size := tab.fitLabel(text, area.W, style)
+	fmt.Fprintf(buf, "  <clipPath id=\"%s\"><rect %s/></clipPath>\n", clipID, rectAttrs(area))
+	fmt.Fprintf(buf, "  <text x=\"%s\" y=\"%s\" clip-path=\"url(#%s)\" font-family=\"&quot;%s&quot;, sans-serif\" font-size=\"%s\" fill=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\">%s</text>\n",
+		num(area.X+area.W/2), num(area.Y+area.H/2), clipID, escape(tab.name), num(size*ptToMM), style.TextColor, escape(text))
+}
+
+// writeQRCode writes a QR code as its background and one path of dark
+// modules
+func writeQRCode(buf *bytes.Buffer, box rect, modules []rect) {
+	fmt.Fprintf(buf, "  <rect class=\"qr-code\" %s fill=\"%s\"/>\n", rectAttrs(box), qrBackground)
+	buf.WriteString("  <path class=\"qr-code\" d=\"")
+	for _, m := range modules {
+		fmt.Fprintf(buf, "M%s %sh%sv%sh-%sz", num(m.X), num(m.Y), num(m.W), num(m.H), num(m.W))
+	}
+	fmt.Fprintf(buf, "\" fill=\"%s\" shape-rendering=\"crispEdges\"/>\n", qrModuleColor)
+}
+
+// writeOutline writes the separator border, or a dashed cut line, following
+// the tab shape when tabs are staggered
+func (r *SVGRenderer) writeOutline(buf *bytes.Buffer, l *layout.Layout, g faceGeometry) {
+	shape := "<rect " + rectAttrs(g.Outline)
+	if g.Shape != nil {
+		shape = "<polygon " + pointsAttr(g.Shape)
+	}
+	if l.Config.ShowCutLines {
+		fmt.Fprintf(buf, "  %s fill=\"none\" stroke=\"%s\" stroke-width=\"0.26\" stroke-dasharray=\"1 1\"/>\n",
+			shape, r.style.CutLineColor)
+		return
+	}
+	if r.style.BorderWidth > 0 {
+		fmt.Fprintf(buf, "  %s fill=\"none\" stroke=\"%s\" stroke-width=\"%s\"/>\n",
+			shape, r.style.BorderColor, num(r.style.BorderWidth))
+	}
+}
+
+// writeCropMarks writes the corner crop marks for every placement as one group
+func writeCropMarks(buf *bytes.Buffer, l *layout.Layout, placements []layout.Placement) {
+	fmt.Fprintf(buf, "<g id=\"crop-marks\" stroke=\"#000000\" stroke-width=\"%s\">\n", num(cropMarkWeight))
+	for _, pl := range placements {
+		for _, m := range cropMarks(pl, l.Config) {
+			fmt.Fprintf(buf, "  <line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\"/>\n", num(m.X1), num(m.Y1), num(m.X2), num(m.Y2))
+		}
+	}
+	buf.WriteString("</g>\n")
+}
+
+// writeRegistrationMarks writes the layout's print-and-cut registration
+// marks as one group
+func writeRegistrationMarks(buf *bytes.Buffer, l *layout.Layout) {
+	if len(l.Registration) == 0 {
+		return
+	}
+	buf.WriteString("<g id=\"registration-marks\" fill=\"#000000\">\n")
+	for _, m := range l.Registration {
+		fmt.Fprintf(buf, "  <rect %s/>\n", rectAttrs(boxRect(m)))
+	}
+	buf.WriteString("</g>\n")
+}
+
+// rectAttrs formats the position and size attributes of a rect
+func rectAttrs(r rect) string {
+	return fmt.Sprintf("x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"", num(r.X), num(r.Y), num(r.W), num(r.H))
+}
+
+// pointsAttr formats a polygon's points attribute
+func pointsAttr(pts []point) string {
+	coords := make([]string, len(pts))
+	for i, p := range pts {
+		coords[i] = num(p.X) + "," + num(p.Y)
+	}
+	return "points=\"" + strings.Join(coords, " ") + "\""
+}
+
+// num formats millimetres with a fixed precision so output is stable
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// escape escapes text for use in XML content and attributes
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
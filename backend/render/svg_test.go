@@ -0,0 +1,89 @@
+package render
+
+import (
+	"bytes"
+	"card-separator/database"
+	"card-separator/layout"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// embeddedFont matches the tab font data URI of an SVG page
+var embeddedFont = regexp.MustCompile(`data:font/ttf;base64,([A-Za-z0-9+/=]+)`)
+
+// redactFont swaps the embedded font for its hash, keeping golden files
+// small while still pinning the font bytes
+func redactFont(t *testing.T, svg []byte) []byte {
+	t.Helper()
+	return embeddedFont.ReplaceAllFunc(svg, func(m []byte) []byte {
+		data, err := base64.StdEncoding.DecodeString(string(embeddedFont.FindSubmatch(m)[1]))
+		if err != nil {
+			t.Fatalf("embedded font is not base64: %v", err)
+		}
+		sum := sha256.Sum256(data)
+		return []byte("data:font/ttf;sha256," + hex.EncodeToString(sum[:]))
+	})
+}
+
+func goldenLayout(t *testing.T) *layout.Layout {
+	t.Helper()
+	cards := []database.Card{
+		{CardSetID: "OP01-001", CardName: "Roronoa Zoro", SetID: "OP-01", SetName: "Romance Dawn", CardColor: "Red", CardType: "LEADER", Rarity: "L"},
+		{CardSetID: "OP01-002", CardName: "Trafalgar Law", SetID: "OP-01", SetName: "Romance Dawn", CardColor: "Red Green", CardType: "LEADER", Rarity: "L"},
+		{CardSetID: "OP01-003", CardName: "Monkey.D.Luffy", SetID: "OP-01", SetName: "Romance Dawn", CardColor: "Red", CardType: "LEADER", Rarity: "L"},
+	}
+	cfg := layout.DefaultPrintConfig()
+	cfg.ShowImages = false
+	cfg.CropMarks = true
+	l, err := layout.Build(cards, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Pages) != 1 {
+		t.Fatalf("golden layout has %d pages, want 1", len(l.Pages))
+	}
+	return l
+}
+
+func TestSVGGolden(t *testing.T) {
+	l := goldenLayout(t)
+	r := NewSVGRenderer(nil, nil, DefaultStyle())
+
+	var buf bytes.Buffer
+	if err := r.RenderPage(context.Background(), l, 1, &buf); err != nil {
+		t.Fatal(err)
+	}
+	got := redactFont(t, buf.Bytes())
+
+	golden := filepath.Join("testdata", "page.golden.svg")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test ./render -run SVGGolden -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("SVG page differs from %s; run go test ./render -run SVGGolden -update and review the diff", golden)
+	}
+
+	// Rendering is deterministic
+	var again bytes.Buffer
+	if err := r.RenderPage(context.Background(), l, 1, &again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("rendering the same page twice gave different SVGs")
+	}
+}
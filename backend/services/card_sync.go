@@ -1,105 +1,142 @@
 package services
 
 import (
+	"card-separator/cardsource"
 	"card-separator/database"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
-	"strconv"
 	"time"
 )
 
 type CardSyncService struct {
-	db         *database.DB
-	httpClient *http.Client
+	db     *database.DB
+	source cardsource.CardSource
 }
 
-// APICard represents a card from the OPTCG API
-type APICard struct {
-	CardName      string `json:"card_name"`
-	CardSetID     string `json:"card_set_id"`
-	CardCost      string `json:"card_cost"`
-	CardPower     string `json:"card_power"`
-	CardColor     string `json:"card_color"`
-	CardType      string `json:"card_type"`
-	Rarity        string `json:"rarity"`
-	Attribute     string `json:"attribute"`
-	CardText      string `json:"card_text"`
-	CardImage     string `json:"card_image"`
-	SetID         string `json:"set_id"`
-	SetName       string `json:"set_name"`
-	CounterAmount int    `json:"counter_amount"`
+// NewCardSyncService creates a new card sync service reading from source
+func NewCardSyncService(db *database.DB, source cardsource.CardSource) *CardSyncService {
+	return &CardSyncService{
+		db:     db,
+		source: source,
+	}
 }
 
-// NewCardSyncService creates a new card sync service
-func NewCardSyncService(db *database.DB) *CardSyncService {
-	return &CardSyncService{
-		db: db,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+// SyncSetCards fetches all cards for a specific set from the card source
+// and replaces the set's cached cards with them in one transaction, retiring
+// cards the source no longer lists. The request is conditional on the last
+// sync unless force is set, and a payload whose hash matches the last sync
+// is not compared card by card. A dry run reports the same counts but rolls
+// the replacement back and leaves the sync state alone. The run is recorded
+// under trigger.
+func (s *CardSyncService) SyncSetCards(trigger, setID string, force, dryRun bool) (*SyncResult, error) {
+	started := time.Now()
+	result, err := s.syncSetCards(setID, force, dryRun)
+	recordSyncRun(s.db, trigger, cardsScope(setID), started, dryRun, result, err)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
 }
 
-// SyncSetCards fetches all cards for a specific set from OPTCG API
-func (s *CardSyncService) SyncSetCards(setID string) (int, error) {
-	log.Printf("[SYNC] Fetching cards for set %s from OPTCG API...", setID)
+// syncSetCards does the work of SyncSetCards. Failures after the source
+// answered return the result so far along with the error.
+func (s *CardSyncService) syncSetCards(setID string, force, dryRun bool) (*SyncResult, error) {
+	log.Printf("[SYNC] Fetching cards for set %s from %s...", setID, s.source.Name())
 
-	// Fetch from OPTCG API
-	url := fmt.Sprintf("https://optcgapi.com/api/sets/%s/", setID)
-	resp, err := s.httpClient.Get(url)
+	scope := cardsScope(setID)
+	state, err := s.db.GetSyncState(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sync state: %w", err)
+	}
+	cached, err := s.db.GetCardsBySet(setID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch cards from API: %w", err)
+		return nil, fmt.Errorf("failed to fetch cached cards: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code from OPTCG API: %d", resp.StatusCode)
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	list, err := s.source.ListCards(ctx, setID, syncValidators(state, len(cached), force))
+	if err != nil {
+		return nil, err
 	}
 
-	var apiCards []APICard
-	if err := json.NewDecoder(resp.Body).Decode(&apiCards); err != nil {
-		return 0, fmt.Errorf("failed to decode cards response: %w", err)
+	now := time.Now()
+	result := &SyncResult{Scope: scope, DryRun: dryRun, HTTPStatus: list.HTTPStatus, SyncedAt: now}
+	if list.NotModified && state != nil {
+		result.NotModified = true
+		result.Unchanged = len(cached)
+		log.Printf("[SYNC] Cards for set %s not modified (%d cached)", setID, len(cached))
+		if dryRun {
+			return result, nil
+		}
+		return result, saveSyncState(s.db, nextSyncState(state, scope, list.Validators, state.ContentHash, len(cached), now))
 	}
 
-	// Convert and upsert cards
-	count := 0
-	for _, apiCard := range apiCards {
-		card := s.convertAPICard(&apiCard)
-		if err := s.db.UpsertCard(card); err != nil {
-			log.Printf("[SYNC] Warning: failed to upsert card %s: %v", card.CardSetID, err)
-			continue
+	hash := cardDataVersion(list.Cards)
+	if !force && state != nil && state.ContentHash == hash && state.ItemCount == len(cached) {
+		// The cache is as the last sync of this payload left it
+		result.Unchanged = len(cached)
+		log.Printf("[SYNC] Cards for set %s unchanged (%d cached)", setID, len(cached))
+		if dryRun {
+			return result, nil
 		}
-		count++
+		return result, saveSyncState(s.db, nextSyncState(state, scope, list.Validators, hash, len(cached), now))
 	}
 
-	// Update set card count
-	if err := s.db.UpdateSetCardCount(setID, count); err != nil {
-		log.Printf("[SYNC] Warning: failed to update set card count: %v", err)
+	if len(list.Cards) == 0 && len(cached) > 0 {
+		// More likely a broken source than a set that lost every card
+		return result, fmt.Errorf("%s listed no cards for set %s, keeping %d cached cards", s.source.Name(), setID, len(cached))
 	}
 
-	log.Printf("[SYNC] Successfully synced %d cards for set %s", count, setID)
-	return count, nil
-}
+	cards := dedupeCards(list.Cards)
+	versions := make(map[string]string, len(cached))
+	for _, card := range cached {
+		versions[card.CardSetID] = cardDataVersion([]database.Card{card})
+	}
+	for _, card := range cards {
+		version, ok := versions[card.CardSetID]
+		switch {
+		case !ok:
+			result.Added++
+		case version != cardDataVersion([]database.Card{card}):
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
 
-func (s *CardSyncService) convertAPICard(apiCard *APICard) *database.Card {
-	// Parse cost and power as integers
-	cost, _ := strconv.Atoi(apiCard.CardCost)
-	power, _ := strconv.Atoi(apiCard.CardPower)
+	result.Removed, err = s.db.ReplaceSetCards(setID, cards, dryRun)
+	if err != nil {
+		return result, fmt.Errorf("failed to replace cards for set %s: %w", setID, err)
+	}
+	if dryRun {
+		log.Printf("[SYNC] Dry run for set %s rolled back (%d added, %d updated, %d unchanged, %d retired)",
+			setID, result.Added, result.Updated, result.Unchanged, result.Removed)
+		return result, nil
+	}
+	if err := saveSyncState(s.db, nextSyncState(state, scope, list.Validators, hash, result.Total(), now)); err != nil {
+		return result, err
+	}
+
+	log.Printf("[SYNC] Successfully synced %d cards for set %s (%d added, %d updated, %d unchanged, %d retired)",
+		result.Total(), setID, result.Added, result.Updated, result.Unchanged, result.Removed)
+	return result, nil
+}
 
-	return &database.Card{
-		CardSetID:    apiCard.CardSetID,
-		CardName:     apiCard.CardName,
-		SetID:        apiCard.SetID,
-		SetName:      apiCard.SetName,
-		CardImageURL: apiCard.CardImage,
-		CardColor:    apiCard.CardColor,
-		CardType:     apiCard.CardType,
-		CardCost:     cost,
-		CardPower:    power,
-		Rarity:       apiCard.Rarity,
-		Attribute:    apiCard.Attribute,
-		CardText:     apiCard.CardText,
+// dedupeCards keeps one entry per card ID, the last listed as that is the
+// one an upsert of every entry would store, in order of first listing
+func dedupeCards(cards []database.Card) []database.Card {
+	index := make(map[string]int, len(cards))
+	deduped := make([]database.Card, 0, len(cards))
+	for _, card := range cards {
+		if i, ok := index[card.CardSetID]; ok {
+			deduped[i] = card
+			continue
+		}
+		index[card.CardSetID] = len(deduped)
+		deduped = append(deduped, card)
 	}
+	return deduped
 }
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"card-separator/database"
+	"card-separator/fonts"
+	"card-separator/storage"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	// ErrFontNotFound is returned when a font name is neither bundled nor uploaded
+	ErrFontNotFound = errors.New("font not found")
+	// ErrBundledFont is returned when trying to replace or delete a bundled font
+	ErrBundledFont = errors.New("bundled fonts cannot be changed")
+	// ErrInvalidFont is returned when an upload's name or file is unusable
+	ErrInvalidFont = errors.New("invalid font")
+)
+
+// FontInfo describes a font in the registry
+type FontInfo struct {
+	Name          string `json:"name"`
+	Family        string `json:"family"`
+	Bundled       bool   `json:"bundled"`
+	FileSizeBytes int64  `json:"file_size_bytes"`
+	SHA256        string `json:"sha256,omitempty"`
+}
+
+// FontService is the font registry: the bundled fonts plus uploads stored
+// in MinIO. Upload files are stored by content hash, and a replaced or
+// deleted version is kept while a saved print job still uses it.
+type FontService struct {
+	db      *database.DB
+	storage *storage.MinIOStorage
+}
+
+// NewFontService creates a new font service
+func NewFontService(db *database.DB, storage *storage.MinIOStorage) *FontService {
+	return &FontService{db: db, storage: storage}
+}
+
+// ListFonts returns the bundled fonts followed by the uploaded ones
+func (s *FontService) ListFonts() ([]FontInfo, error) {
+	var list []FontInfo
+	for _, name := range fonts.BundledNames() {
+		data, _ := fonts.Bundled(name)
+		family, _ := fonts.Parse(data)
+		list = append(list, FontInfo{Name: name, Family: family, Bundled: true, FileSizeBytes: int64(len(data))})
+	}
+
+	uploaded, err := s.db.GetAllFonts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fonts: %w", err)
+	}
+	for _, f := range uploaded {
+		list = append(list, FontInfo{Name: f.Name, Family: f.Family, FileSizeBytes: f.FileSizeBytes, SHA256: f.SHA256})
+	}
+	return list, nil
+}
+
+// HasFont reports whether name is a bundled or uploaded font
+func (s *FontService) HasFont(name string) (bool, error) {
+	if _, ok := fonts.Bundled(name); ok {
+		return true, nil
+	}
+	font, err := s.db.GetFont(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch font %s: %w", name, err)
+	}
+	return font != nil, nil
+}
+
+// GetFontVersion returns the file of a bundled or uploaded font. A set
+// version selects the upload with that SHA-256, even if name has since been
+// replaced or deleted.
+func (s *FontService) GetFontVersion(ctx context.Context, name, version string) ([]byte, error) {
+	if _, ok := fonts.Bundled(name); ok || version == "" {
+		return s.GetFont(ctx, name)
+	}
+	data, err := s.storage.Get(ctx, fontKey(version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch font %s version %s: %w", name, version, err)
+	}
+	return data, nil
+}
+
+// GetFont returns the file of a bundled or uploaded font
+func (s *FontService) GetFont(ctx context.Context, name string) ([]byte, error) {
+	if data, ok := fonts.Bundled(name); ok {
+		return data, nil
+	}
+
+	font, err := s.db.GetFont(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch font %s: %w", name, err)
+	}
+	if font == nil {
+		return nil, fmt.Errorf("%s: %w", name, ErrFontNotFound)
+	}
+	return s.storage.Get(ctx, font.MinioObjectKey)
+}
+
+// Version returns the SHA-256 of an uploaded font's file, or "" for a
+// bundled font, which only changes with the binary. Fonts uploaded before
+// hashes were recorded are moved to their content-hash key first, so the
+// version can be fetched by GetFontVersion.
+func (s *FontService) Version(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		name = fonts.Default
+	}
+	if _, ok := fonts.Bundled(name); ok {
+		return "", nil
+	}
+
+	font, err := s.db.GetFont(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch font %s: %w", name, err)
+	}
+	if font == nil {
+		return "", fmt.Errorf("%s: %w", name, ErrFontNotFound)
+	}
+	if font.SHA256 != "" {
+		return font.SHA256, nil
+	}
+
+	data, err := s.storage.Get(ctx, font.MinioObjectKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch font %s: %w", name, err)
+	}
+	legacyKey := font.MinioObjectKey
+	font.SHA256 = fontHash(data)
+	font.MinioObjectKey = fontKey(font.SHA256)
+	if err := s.storage.Put(ctx, font.MinioObjectKey, data, "font/ttf"); err != nil {
+		return "", fmt.Errorf("failed to store font %s: %w", name, err)
+	}
+	if err := s.db.UpsertFont(font); err != nil {
+		return "", fmt.Errorf("failed to save font %s: %w", name, err)
+	}
+	s.release(ctx, legacyKey, "")
+	return font.SHA256, nil
+}
+
+// SaveFont validates and stores an uploaded font, replacing any font
+// already saved under name
+func (s *FontService) SaveFont(ctx context.Context, name string, data []byte) (*database.Font, error) {
+	if _, ok := fonts.Bundled(name); ok {
+		return nil, fmt.Errorf("%s: %w", name, ErrBundledFont)
+	}
+	if err := fonts.ValidateName(name); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFont, err)
+	}
+	family, err := fonts.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFont, err)
+	}
+	previous, err := s.db.GetFont(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch font %s: %w", name, err)
+	}
+
+	sum := fontHash(data)
+	font := &database.Font{
+		Name:           name,
+		Family:         family,
+		MinioObjectKey: fontKey(sum),
+		FileSizeBytes:  int64(len(data)),
+		SHA256:         sum,
+	}
+	if err := s.storage.Put(ctx, font.MinioObjectKey, data, "font/ttf"); err != nil {
+		return nil, fmt.Errorf("failed to store font %s: %w", name, err)
+	}
+	if err := s.db.UpsertFont(font); err != nil {
+		return nil, fmt.Errorf("failed to save font %s: %w", name, err)
+	}
+
+	if previous != nil && previous.MinioObjectKey != font.MinioObjectKey {
+		s.release(ctx, previous.MinioObjectKey, previous.SHA256)
+	}
+
+	log.Printf("[FONT] Saved %s (%s, %d bytes)", name, family, len(data))
+	return s.db.GetFont(name)
+}
+
+// DeleteFont removes an uploaded font and its file
+func (s *FontService) DeleteFont(ctx context.Context, name string) error {
+	if _, ok := fonts.Bundled(name); ok {
+		return fmt.Errorf("%s: %w", name, ErrBundledFont)
+	}
+
+	font, err := s.db.GetFont(name)
+	if err != nil {
+		return fmt.Errorf("failed to fetch font %s: %w", name, err)
+	}
+	if font == nil {
+		return fmt.Errorf("%s: %w", name, ErrFontNotFound)
+	}
+	if _, err := s.db.DeleteFont(name); err != nil {
+		return fmt.Errorf("failed to delete font %s: %w", name, err)
+	}
+	s.release(ctx, font.MinioObjectKey, font.SHA256)
+	return nil
+}
+
+// ReleaseVersion removes the stored file of a font version once neither an
+// uploaded font nor a saved print job uses it
+func (s *FontService) ReleaseVersion(ctx context.Context, version string) {
+	if version != "" {
+		s.release(ctx, fontKey(version), version)
+	}
+}
+
+// release removes a font file no longer current for its name, unless its
+// version is still in use. Files without a recorded version predate print
+// job pinning, so nothing can use them.
+func (s *FontService) release(ctx context.Context, objectKey, version string) {
+	if version != "" {
+		inUse, err := s.db.FontVersionInUse(version)
+		if err != nil {
+			log.Printf("[FONT] Warning: keeping %s, failed to check its use: %v", objectKey, err)
+			return
+		}
+		if inUse {
+			return
+		}
+	}
+	if err := s.storage.Delete(ctx, objectKey); err != nil {
+		log.Printf("[FONT] Warning: failed to delete %s: %v", objectKey, err)
+	}
+}
+
+// fontKey returns the object key of the font file with the given SHA-256
+func fontKey(version string) string {
+	return "fonts/" + version + ".ttf"
+}
+
+// fontHash returns the hex SHA-256 of a font file
+func fontHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
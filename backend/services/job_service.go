@@ -0,0 +1,228 @@
+package services
+
+import (
+	"card-separator/database"
+	"card-separator/layout"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrJobNotFound is returned when a print job ID does not exist
+var ErrJobNotFound = errors.New("print job not found")
+
+// JobRequest names a print job and selects its cards and print settings
+type JobRequest struct {
+	Name string `json:"name"`
+	LayoutRequest
+}
+
+// PrintJob is a saved job with whether its cards have changed since
+type PrintJob struct {
+	database.PrintJob
+	Config       layout.PrintConfig `json:"config"`
+	Cards        []database.Card    `json:"cards,omitempty"`
+	CardsChanged bool               `json:"cards_changed"` // Cached card data differs from the snapshot
+}
+
+// PrintJobService saves print jobs and rebuilds their layouts from the
+// snapshot, so reprints do not pick up later card syncs. Uploaded fonts are
+// pinned by hash, so reprints use the font file the job was saved with
+// even after it is replaced.
+type PrintJobService struct {
+	db      *database.DB
+	layouts *LayoutService
+}
+
+// NewPrintJobService creates a new print job service
+func NewPrintJobService(db *database.DB, layouts *LayoutService) *PrintJobService {
+	return &PrintJobService{db: db, layouts: layouts}
+}
+
+// CreateJob resolves the request's cards, printer offset and font version
+// and saves them, in order, with the print config
+func (s *PrintJobService) CreateJob(ctx context.Context, req *JobRequest) (*PrintJob, error) {
+	cards, err := s.layouts.ResolveCards(&req.LayoutRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := req.LayoutRequest
+	snapshot.Cards = cards
+	// Snapshot the printer's current offset rather than the profile name
+	snapshot.Printer = ""
+	var offset layout.Offset
+	if req.Printer != "" {
+		if offset, err = s.layouts.PrinterOffset(req.Printer); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build once so invalid jobs are rejected before they are saved
+	if _, err := s.layouts.build(cards, &snapshot, ""); err != nil {
+		return nil, err
+	}
+	fontVersion, err := s.layouts.fonts.Version(ctx, snapshot.Font)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode print job: %w", err)
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Print job " + id
+	}
+
+	job := database.PrintJob{
+		ID:              id,
+		Name:            name,
+		Request:         data,
+		CardCount:       len(cards),
+		CardDataVersion: cardDataVersion(cards),
+		FontSHA256:      fontVersion,
+		BackOffsetX:     offset.X,
+		BackOffsetY:     offset.Y,
+		CreatedAt:       time.Now().UTC().Truncate(time.Second),
+	}
+	if err := s.db.SavePrintJob(&job); err != nil {
+		return nil, fmt.Errorf("failed to save print job: %w", err)
+	}
+	return &PrintJob{PrintJob: job, Config: snapshot.PrintConfig, Cards: cards}, nil
+}
+
+// ListJobs returns every saved job, newest first
+func (s *PrintJobService) ListJobs() ([]database.PrintJob, error) {
+	jobs, err := s.db.GetAllPrintJobs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch print jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetJob returns a saved job with its snapshotted cards, comparing them
+// against the currently cached card data
+func (s *PrintJobService) GetJob(id string) (*PrintJob, error) {
+	job, snapshot, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.db.GetCardsByIDs(cardSetIDs(snapshot.Cards))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch current cards: %w", err)
+	}
+	return &PrintJob{
+		PrintJob:     *job,
+		Config:       snapshot.PrintConfig,
+		Cards:        snapshot.Cards,
+		CardsChanged: cardsChanged(snapshot.Cards, current),
+	}, nil
+}
+
+// BuildJobLayout rebuilds a job's layout from its snapshot alone, set in
+// the version of its font the job was saved with
+func (s *PrintJobService) BuildJobLayout(id string) (*layout.Layout, error) {
+	job, snapshot, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.layouts.build(snapshot.Cards, snapshot, job.FontSHA256)
+	if err != nil {
+		return nil, err
+	}
+	result.BackOffset = layout.Offset{X: job.BackOffsetX, Y: job.BackOffsetY}
+	result.CreatedAt = job.CreatedAt
+	return result, nil
+}
+
+// DeleteJob removes a saved job, and the version of its font if nothing
+// else uses it
+func (s *PrintJobService) DeleteJob(ctx context.Context, id string) error {
+	job, err := s.db.GetPrintJob(id)
+	if err != nil {
+		return fmt.Errorf("failed to fetch print job %s: %w", id, err)
+	}
+	if job == nil {
+		return fmt.Errorf("%s: %w", id, ErrJobNotFound)
+	}
+	deleted, err := s.db.DeletePrintJob(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete print job %s: %w", id, err)
+	}
+	if !deleted {
+		return fmt.Errorf("%s: %w", id, ErrJobNotFound)
+	}
+	s.layouts.fonts.ReleaseVersion(ctx, job.FontSHA256)
+	return nil
+}
+
+// load fetches a job and decodes its snapshotted request
+func (s *PrintJobService) load(id string) (*database.PrintJob, *LayoutRequest, error) {
+	job, err := s.db.GetPrintJob(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch print job %s: %w", id, err)
+	}
+	if job == nil {
+		return nil, nil, fmt.Errorf("%s: %w", id, ErrJobNotFound)
+	}
+
+	req := NewLayoutRequest()
+	if err := json.Unmarshal(job.Request, &req); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode print job %s: %w", id, err)
+	}
+	return job, &req, nil
+}
+
+// cardDataVersion hashes the printed content of cards in order. Row IDs and
+// timestamps are left out so re-syncing unchanged cards keeps the version.
+func cardDataVersion(cards []database.Card) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, card := range cards {
+		card.ID = 0
+		card.CreatedAt = time.Time{}
+		enc.Encode(card)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// cardsChanged reports whether any snapshotted card is missing from or
+// differs in current
+func cardsChanged(snapshot, current []database.Card) bool {
+	byID := make(map[string]database.Card, len(current))
+	for _, card := range current {
+		byID[card.CardSetID] = card
+	}
+	for _, card := range snapshot {
+		if layout.IsBlank(card) || layout.IsSetHeader(card) {
+			continue
+		}
+		now, ok := byID[card.CardSetID]
+		if !ok || cardDataVersion([]database.Card{now}) != cardDataVersion([]database.Card{card}) {
+			return true
+		}
+	}
+	return false
+}
+
+// cardSetIDs returns the card IDs of cards
+func cardSetIDs(cards []database.Card) []string {
+	ids := make([]string, len(cards))
+	for i, card := range cards {
+		ids[i] = card.CardSetID
+	}
+	return ids
+}
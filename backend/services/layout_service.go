@@ -0,0 +1,231 @@
+package services
+
+import (
+	"card-separator/database"
+	"card-separator/layout"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoCards is returned when a layout request resolves to an empty card list
+	ErrNoCards = errors.New("no cards to lay out")
+	// ErrLayoutNotFound is returned when a saved layout ID does not exist
+	ErrLayoutNotFound = errors.New("layout not found")
+	// ErrPrinterNotFound is returned when a request names an unknown printer profile
+	ErrPrinterNotFound = errors.New("printer profile not found")
+)
+
+// LayoutRequest selects the cards to lay out plus the print settings
+type LayoutRequest struct {
+	SetID      string          `json:"set_id,omitempty"`
+	SetIDs     []string        `json:"set_ids,omitempty"` // Ordered sets for a multi-set collection
+	Cards      []database.Card `json:"cards,omitempty"`
+	GroupBy    []string        `json:"group_by,omitempty"`    // Card fields giving one separator per group
+	GroupLabel string          `json:"group_label,omitempty"` // Label template such as "{card_color} · Cost {card_cost}"
+	layout.PrintConfig
+}
+
+// NewLayoutRequest returns a request pre-filled with the default print config
+func NewLayoutRequest() LayoutRequest {
+	return LayoutRequest{PrintConfig: layout.DefaultPrintConfig()}
+}
+
+type LayoutService struct {
+	db       *database.DB
+	fonts    *FontService
+	profiles *ProfileService
+}
+
+// NewLayoutService creates a new layout service
+func NewLayoutService(db *database.DB, fonts *FontService, profiles *ProfileService) *LayoutService {
+	return &LayoutService{db: db, fonts: fonts, profiles: profiles}
+}
+
+// Validate checks the print config and grouping options
+func (r LayoutRequest) Validate() error {
+	if err := r.PrintConfig.Validate(); err != nil {
+		return err
+	}
+	return layout.ValidateGrouping(r.GroupBy, r.GroupLabel)
+}
+
+// ResolveProfiles replaces custom page size and separator profile names in
+// cfg with their dimensions, so it can be validated and saved
+func (s *LayoutService) ResolveProfiles(cfg *layout.PrintConfig) error {
+	return s.profiles.Resolve(cfg)
+}
+
+// ResolveCards returns the explicit card list, the cached cards of each set
+// in set_ids behind a set header, or the cached cards for the set
+func (s *LayoutService) ResolveCards(req *LayoutRequest) ([]database.Card, error) {
+	if len(req.Cards) > 0 {
+		return req.Cards, nil
+	}
+	if len(req.SetIDs) > 0 {
+		return s.resolveCollection(req.SetIDs)
+	}
+	if req.SetID == "" {
+		return nil, fmt.Errorf("one of set_id, set_ids or cards is required")
+	}
+
+	cards, err := s.db.GetCardsBySet(req.SetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cards for set %s: %w", req.SetID, err)
+	}
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("set %s: %w", req.SetID, ErrNoCards)
+	}
+	return cards, nil
+}
+
+// resolveCollection joins the cards of several sets into one list, opening
+// each set with a header naming it and its card count. Pairing then runs on
+// across set boundaries.
+func (s *LayoutService) resolveCollection(setIDs []string) ([]database.Card, error) {
+	var collection []database.Card
+	for _, setID := range setIDs {
+		set, err := s.db.GetSet(setID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch set %s: %w", setID, err)
+		}
+		if set == nil {
+			return nil, fmt.Errorf("set %s: %w", setID, ErrNoCards)
+		}
+
+		cards, err := s.db.GetCardsBySet(setID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch cards for set %s: %w", setID, err)
+		}
+		if len(cards) == 0 {
+			return nil, fmt.Errorf("set %s: %w", setID, ErrNoCards)
+		}
+
+		collection = append(collection, layout.CreateSetHeader(*set, len(cards)))
+		collection = append(collection, cards...)
+	}
+	return collection, nil
+}
+
+// BuildLayout resolves the request's cards and builds the print layout
+func (s *LayoutService) BuildLayout(req *LayoutRequest) (*layout.Layout, error) {
+	cards, err := s.ResolveCards(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.build(cards, req, "")
+}
+
+// build checks the request's font, groups and lays out cards, then applies
+// the back offset of the request's printer. A set fontVersion pins the
+// upload the font is rendered from, which need not be current or exist by
+// name any more.
+func (s *LayoutService) build(cards []database.Card, req *LayoutRequest, fontVersion string) (*layout.Layout, error) {
+	if req.Font != "" && fontVersion == "" {
+		ok, err := s.fonts.HasFont(req.Font)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", req.Font, ErrFontNotFound)
+		}
+	}
+
+	cards, err := layout.GroupCards(cards, req.GroupBy, req.GroupLabel)
+	if err != nil {
+		return nil, err
+	}
+	result, err := layout.Build(cards, req.PrintConfig)
+	if err != nil {
+		return nil, err
+	}
+	result.FontVersion = fontVersion
+	if req.Printer == "" {
+		return result, nil
+	}
+
+	offset, err := s.PrinterOffset(req.Printer)
+	if err != nil {
+		return nil, err
+	}
+	result.BackOffset = offset
+	return result, nil
+}
+
+// PrinterOffset returns the stored back-side offset for a printer profile
+func (s *LayoutService) PrinterOffset(name string) (layout.Offset, error) {
+	profile, err := s.db.GetPrinterProfile(name)
+	if err != nil {
+		return layout.Offset{}, fmt.Errorf("failed to fetch printer profile %s: %w", name, err)
+	}
+	if profile == nil {
+		return layout.Offset{}, fmt.Errorf("%s: %w", name, ErrPrinterNotFound)
+	}
+	return layout.Offset{X: profile.OffsetX, Y: profile.OffsetY}, nil
+}
+
+// CreateLayout builds a layout and saves it, with its cards resolved, so its
+// pages can be rendered later by ID
+func (s *LayoutService) CreateLayout(req *LayoutRequest) (*layout.Layout, error) {
+	cards, err := s.ResolveCards(req)
+	if err != nil {
+		return nil, err
+	}
+	saved := *req
+	saved.Cards = cards
+
+	result, err := s.build(cards, &saved, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode layout request: %w", err)
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.SaveLayout(id, data); err != nil {
+		return nil, fmt.Errorf("failed to save layout: %w", err)
+	}
+
+	result.ID = id
+	return result, nil
+}
+
+// GetLayout rebuilds a saved layout by ID
+func (s *LayoutService) GetLayout(id string) (*layout.Layout, error) {
+	saved, err := s.db.GetLayout(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch layout %s: %w", id, err)
+	}
+	if saved == nil {
+		return nil, fmt.Errorf("%s: %w", id, ErrLayoutNotFound)
+	}
+
+	req := NewLayoutRequest()
+	if err := json.Unmarshal(saved.Request, &req); err != nil {
+		return nil, fmt.Errorf("failed to decode layout %s: %w", id, err)
+	}
+
+	result, err := s.BuildLayout(&req)
+	if err != nil {
+		return nil, err
+	}
+	result.ID = saved.ID
+	return result, nil
+}
+
+// newID returns a random ID for a saved layout or job
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
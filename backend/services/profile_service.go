@@ -0,0 +1,202 @@
+package services
+
+import (
+	"card-separator/database"
+	"card-separator/layout"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var (
+	// ErrProfileNotFound is returned when a page size or separator profile name is unknown
+	ErrProfileNotFound = errors.New("dimension profile not found")
+	// ErrBuiltinProfile is returned when trying to replace or delete a built-in profile
+	ErrBuiltinProfile = errors.New("built-in profiles cannot be changed")
+	// ErrInvalidProfile is returned when a custom profile's name or dimensions are unusable
+	ErrInvalidProfile = errors.New("invalid dimension profile")
+)
+
+// Profile kinds
+const (
+	ProfilePage = "page"
+	ProfileCard = "card"
+)
+
+// maxProfileSize is the largest profile width or height in mm
+const maxProfileSize = 1000
+
+// validProfileName matches names safe to use in URLs and query strings
+var validProfileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Profile is a named page size or separator size
+type Profile struct {
+	Name      string  `json:"name"`
+	Width     float64 `json:"width_mm"`
+	Height    float64 `json:"height_mm"`
+	TabHeight float64 `json:"tab_height_mm,omitempty"` // Separator profiles only
+	Builtin   bool    `json:"builtin"`
+}
+
+// Catalogue lists every page size and separator profile
+type Catalogue struct {
+	Pages []Profile `json:"pages"`
+	Cards []Profile `json:"cards"`
+}
+
+// ProfileService is the catalogue of built-in page sizes and separator
+// profiles plus custom entries stored in the database
+type ProfileService struct {
+	db *database.DB
+}
+
+// NewProfileService creates a new profile service
+func NewProfileService(db *database.DB) *ProfileService {
+	return &ProfileService{db: db}
+}
+
+// ListProfiles returns the built-in profiles of each kind by name, followed
+// by the custom ones
+func (s *ProfileService) ListProfiles() (*Catalogue, error) {
+	cat := &Catalogue{Pages: []Profile{}, Cards: []Profile{}}
+	for _, name := range sortedKeys(layout.PageSizes) {
+		if name == "custom" {
+			continue
+		}
+		page := layout.PageSizes[name]
+		cat.Pages = append(cat.Pages, Profile{Name: name, Width: page.Width, Height: page.Height, Builtin: true})
+	}
+	for _, name := range sortedKeys(layout.CardProfiles) {
+		card := layout.CardProfiles[name]
+		cat.Cards = append(cat.Cards, Profile{Name: name, Width: card.Width, Height: card.Height, TabHeight: card.TabHeight, Builtin: true})
+	}
+
+	custom, err := s.db.GetAllDimensionProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dimension profiles: %w", err)
+	}
+	for _, p := range custom {
+		profile := Profile{Name: p.Name, Width: p.Width, Height: p.Height, TabHeight: p.TabHeight}
+		if p.Kind == ProfilePage {
+			cat.Pages = append(cat.Pages, profile)
+		} else {
+			cat.Cards = append(cat.Cards, profile)
+		}
+	}
+	return cat, nil
+}
+
+// GetProfile returns a built-in or custom profile
+func (s *ProfileService) GetProfile(kind, name string) (*Profile, error) {
+	if profile, ok := builtinProfile(kind, name); ok {
+		return profile, nil
+	}
+
+	p, err := s.db.GetDimensionProfile(kind, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s profile %s: %w", kind, name, err)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("%s profile %s: %w", kind, name, ErrProfileNotFound)
+	}
+	return &Profile{Name: p.Name, Width: p.Width, Height: p.Height, TabHeight: p.TabHeight}, nil
+}
+
+// SaveProfile validates and stores a custom profile, replacing any profile
+// of the same kind and name
+func (s *ProfileService) SaveProfile(kind string, profile Profile) (*Profile, error) {
+	if _, ok := builtinProfile(kind, profile.Name); ok || profile.Name == "custom" {
+		return nil, fmt.Errorf("%s: %w", profile.Name, ErrBuiltinProfile)
+	}
+	if err := validateProfile(kind, profile); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+	}
+
+	p := &database.DimensionProfile{Kind: kind, Name: profile.Name, Width: profile.Width, Height: profile.Height}
+	if kind == ProfileCard {
+		p.TabHeight = profile.TabHeight
+	}
+	if err := s.db.UpsertDimensionProfile(p); err != nil {
+		return nil, fmt.Errorf("failed to save %s profile %s: %w", kind, profile.Name, err)
+	}
+	return s.GetProfile(kind, profile.Name)
+}
+
+// DeleteProfile removes a custom profile
+func (s *ProfileService) DeleteProfile(kind, name string) error {
+	if _, ok := builtinProfile(kind, name); ok {
+		return fmt.Errorf("%s: %w", name, ErrBuiltinProfile)
+	}
+	deleted, err := s.db.DeleteDimensionProfile(kind, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s profile %s: %w", kind, name, err)
+	}
+	if !deleted {
+		return fmt.Errorf("%s profile %s: %w", kind, name, ErrProfileNotFound)
+	}
+	return nil
+}
+
+// Resolve replaces custom profile names in cfg with their dimensions: a
+// custom page_size becomes a custom page of that size, and a custom
+// card_profile fills card_dimensions when it is unset. Built-in names are
+// left for the layout engine.
+func (s *ProfileService) Resolve(cfg *layout.PrintConfig) error {
+	if _, ok := layout.PageSizes[cfg.PageSize]; !ok && cfg.PageSize != "" {
+		page, err := s.GetProfile(ProfilePage, cfg.PageSize)
+		if err != nil {
+			return err
+		}
+		cfg.PageSize = "custom"
+		cfg.CustomPageSize = &layout.PageDimensions{Width: page.Width, Height: page.Height}
+	}
+
+	if _, ok := layout.CardProfiles[cfg.CardProfile]; !ok && cfg.CardProfile != "" && cfg.CardDimensions == nil {
+		card, err := s.GetProfile(ProfileCard, cfg.CardProfile)
+		if err != nil {
+			return err
+		}
+		cfg.CardDimensions = &layout.CardDimensions{Width: card.Width, Height: card.Height, TabHeight: card.TabHeight}
+	}
+	return nil
+}
+
+// builtinProfile returns the built-in profile of kind called name
+func builtinProfile(kind, name string) (*Profile, bool) {
+	switch kind {
+	case ProfilePage:
+		if page, ok := layout.PageSizes[name]; ok && name != "custom" {
+			return &Profile{Name: name, Width: page.Width, Height: page.Height, Builtin: true}, true
+		}
+	case ProfileCard:
+		if card, ok := layout.CardProfiles[name]; ok {
+			return &Profile{Name: name, Width: card.Width, Height: card.Height, TabHeight: card.TabHeight, Builtin: true}, true
+		}
+	}
+	return nil, false
+}
+
+// validateProfile checks a custom profile's name and dimensions
+func validateProfile(kind string, p Profile) error {
+	if !validProfileName.MatchString(p.Name) {
+		return fmt.Errorf("profile name must be 1-64 letters, digits, '.', '_' or '-'")
+	}
+	if p.Width <= 0 || p.Height <= 0 || p.Width > maxProfileSize || p.Height > maxProfileSize {
+		return fmt.Errorf("width_mm and height_mm must be between 0 and %d", maxProfileSize)
+	}
+	if kind == ProfileCard && (p.TabHeight < 0 || p.TabHeight >= p.Height) {
+		return fmt.Errorf("tab_height_mm must be between 0 and the height")
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"bytes"
+	"card-separator/layout"
+	"card-separator/render"
+	"card-separator/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRenderNotFound is returned when a render task ID is unknown or expired
+	ErrRenderNotFound = errors.New("render not found")
+	// ErrRenderNotReady is returned when downloading a render that is not done
+	ErrRenderNotReady = errors.New("render is not finished")
+	// ErrQueueFull is returned when no more renders can be queued
+	ErrQueueFull = errors.New("render queue is full")
+)
+
+// maxQueuedRenders is how many renders may wait for a worker
+const maxQueuedRenders = 100
+
+// renderPrefix is the MinIO prefix finished renders are stored under
+const renderPrefix = "renders/"
+
+// RenderState is the lifecycle stage of a render task
+type RenderState string
+
+const (
+	RenderQueued  RenderState = "queued"
+	RenderRunning RenderState = "running"
+	RenderDone    RenderState = "done"
+	RenderFailed  RenderState = "failed"
+)
+
+// Finished reports whether the state is final
+func (s RenderState) Finished() bool {
+	return s == RenderDone || s == RenderFailed
+}
+
+// RenderRequest selects what a queued render draws: a saved print job, or
+// cards and print settings like a layout request
+type RenderRequest struct {
+	JobID string `json:"job_id,omitempty"`
+	LayoutRequest
+}
+
+// RenderTask is the status of a queued PDF render
+type RenderTask struct {
+	ID            string      `json:"id"`
+	State         RenderState `json:"state"`
+	Progress      int         `json:"progress"` // Percent
+	Error         string      `json:"error,omitempty"`
+	FileSizeBytes int         `json:"file_size_bytes,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	StartedAt     *time.Time  `json:"started_at,omitempty"`
+	FinishedAt    *time.Time  `json:"finished_at,omitempty"`
+	ExpiresAt     *time.Time  `json:"expires_at,omitempty"` // When the finished file is removed
+}
+
+// renderTask is a queued render with its request and change notifications
+type renderTask struct {
+	RenderTask
+	req       RenderRequest
+	objectKey string
+	changed   chan struct{} // Closed and replaced on every update
+}
+
+// RenderQueue renders PDFs on a pool of background workers and keeps the
+// files in MinIO until they expire. Task status is held in memory; files
+// left behind by a restart are removed once they pass the TTL.
+type RenderQueue struct {
+	layouts *LayoutService
+	jobs    *PrintJobService
+	pdf     *render.PDFRenderer
+	storage *storage.MinIOStorage
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	tasks map[string]*renderTask
+	queue chan *renderTask
+}
+
+// NewRenderQueue creates a render queue. Call Start to run its workers.
+func NewRenderQueue(layouts *LayoutService, jobs *PrintJobService, pdf *render.PDFRenderer, storage *storage.MinIOStorage, timeout, ttl time.Duration) *RenderQueue {
+	return &RenderQueue{
+		layouts: layouts,
+		jobs:    jobs,
+		pdf:     pdf,
+		storage: storage,
+		timeout: timeout,
+		ttl:     ttl,
+		tasks:   make(map[string]*renderTask),
+		queue:   make(chan *renderTask, maxQueuedRenders),
+	}
+}
+
+// Start runs workers render workers and a janitor that removes expired
+// renders
+func (q *RenderQueue) Start(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	go func() {
+		interval := q.ttl / 4
+		if interval > time.Hour || interval <= 0 {
+			interval = time.Hour
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			q.expire()
+			<-ticker.C
+		}
+	}()
+	log.Printf("[RENDER] Started %d render workers (files kept %v)", workers, q.ttl)
+}
+
+// Enqueue queues a render, returning its initial status. A job render
+// fails with ErrJobNotFound here rather than on the worker when the job
+// does not exist.
+func (q *RenderQueue) Enqueue(req RenderRequest) (*RenderTask, error) {
+	if req.JobID != "" {
+		if _, _, err := q.jobs.load(req.JobID); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	task := &renderTask{
+		RenderTask: RenderTask{ID: id, State: RenderQueued, CreatedAt: time.Now()},
+		req:        req,
+		objectKey:  renderPrefix + id + ".pdf",
+		changed:    make(chan struct{}),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case q.queue <- task:
+	default:
+		return nil, ErrQueueFull
+	}
+	q.tasks[id] = task
+	status := task.RenderTask
+	return &status, nil
+}
+
+// Status returns a render's current status
+func (q *RenderQueue) Status(id string) (*RenderTask, error) {
+	status, _, err := q.Watch(id)
+	return status, err
+}
+
+// Watch returns a render's current status and a channel closed on its next
+// change
+func (q *RenderQueue) Watch(id string) (*RenderTask, <-chan struct{}, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	task, ok := q.tasks[id]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: %w", id, ErrRenderNotFound)
+	}
+	status := task.RenderTask
+	return &status, task.changed, nil
+}
+
+// File returns a finished render's PDF
+func (q *RenderQueue) File(ctx context.Context, id string) ([]byte, error) {
+	q.mu.Lock()
+	task, ok := q.tasks[id]
+	var state RenderState
+	if ok {
+		state = task.State
+	}
+	q.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, ErrRenderNotFound)
+	}
+	if state != RenderDone {
+		return nil, fmt.Errorf("%s is %s: %w", id, state, ErrRenderNotReady)
+	}
+	return q.storage.Get(ctx, task.objectKey)
+}
+
+// work runs queued renders until the process exits
+func (q *RenderQueue) work() {
+	for task := range q.queue {
+		q.update(task, func(t *RenderTask) {
+			now := time.Now()
+			t.State = RenderRunning
+			t.StartedAt = &now
+		})
+
+		size, err := q.runSafely(task)
+
+		q.update(task, func(t *RenderTask) {
+			now := time.Now()
+			t.FinishedAt = &now
+			if err != nil {
+				t.State = RenderFailed
+				t.Error = err.Error()
+				return
+			}
+			expires := now.Add(q.ttl)
+			t.State = RenderDone
+			t.Progress = 100
+			t.FileSizeBytes = size
+			t.ExpiresAt = &expires
+		})
+		if err != nil {
+			log.Printf("[RENDER] Render %s failed: %v", task.ID, err)
+		} else {
+			log.Printf("[RENDER] Render %s done (%d bytes)", task.ID, size)
+		}
+	}
+}
+
+// runSafely runs a task, turning a panic while building or rendering into
+// a failed task instead of a crashed server
+func (q *RenderQueue) runSafely(task *renderTask) (size int, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("[RENDER] Render %s panicked: %v\n%s", task.ID, p, debug.Stack())
+			size, err = 0, fmt.Errorf("render panicked: %v", p)
+		}
+	}()
+	return q.run(task)
+}
+
+// run builds and renders a task's layout and stores the PDF
+func (q *RenderQueue) run(task *renderTask) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+	defer cancel()
+
+	var result *layout.Layout
+	var err error
+	if task.req.JobID != "" {
+		result, err = q.jobs.BuildJobLayout(task.req.JobID)
+	} else {
+		result, err = q.layouts.BuildLayout(&task.req.LayoutRequest)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	err = q.pdf.RenderProgress(ctx, result, &buf, func(done, total int) {
+		// Hold back 100% until the file is stored
+		percent := done * 99 / total
+		q.update(task, func(t *RenderTask) { t.Progress = percent })
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to render PDF: %w", err)
+	}
+
+	if err := q.storage.Put(ctx, task.objectKey, buf.Bytes(), "application/pdf"); err != nil {
+		return 0, fmt.Errorf("failed to store PDF: %w", err)
+	}
+	return buf.Len(), nil
+}
+
+// update applies change to a task's status and wakes its watchers
+func (q *RenderQueue) update(task *renderTask, change func(*RenderTask)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	before := task.RenderTask
+	change(&task.RenderTask)
+	if task.RenderTask == before {
+		return
+	}
+	close(task.changed)
+	task.changed = make(chan struct{})
+}
+
+// expire forgets renders whose files have expired and removes stored files
+// older than the TTL
+func (q *RenderQueue) expire() {
+	now := time.Now()
+	q.mu.Lock()
+	for id, task := range q.tasks {
+		if task.State.Finished() && task.FinishedAt.Add(q.ttl).Before(now) {
+			delete(q.tasks, id)
+		}
+	}
+	q.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	removed, err := q.storage.RemoveOlderThan(ctx, renderPrefix, q.ttl)
+	if err != nil {
+		log.Printf("[RENDER] Warning: failed to remove expired renders: %v", err)
+	}
+	if removed > 0 {
+		log.Printf("[RENDER] Removed %d expired renders", removed)
+	}
+}
@@ -1,63 +1,103 @@
 package services
 
 import (
+	"card-separator/cardsource"
 	"card-separator/database"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
 	"time"
 )
 
 type SetSyncService struct {
-	db         *database.DB
-	httpClient *http.Client
+	db     *database.DB
+	source cardsource.CardSource
 }
 
-type OPTCGSet struct {
-	SetID   string `json:"set_id"`
-	SetName string `json:"set_name"`
+// NewSetSyncService creates a new set sync service reading from source
+func NewSetSyncService(db *database.DB, source cardsource.CardSource) *SetSyncService {
+	return &SetSyncService{
+		db:     db,
+		source: source,
+	}
 }
 
-// NewSetSyncService creates a new set sync service
-func NewSetSyncService(db *database.DB) *SetSyncService {
-	return &SetSyncService{
-		db: db,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+// SyncAllSets fetches all sets from the card source, caches new and renamed
+// ones and marks every listed set as synced, recording the run under
+// trigger. The request is conditional on the last sync unless force is set.
+// Sets the source stops listing are kept and not counted.
+func (s *SetSyncService) SyncAllSets(trigger string, force bool) (*SyncResult, error) {
+	started := time.Now()
+	result, err := s.syncAllSets(force)
+	recordSyncRun(s.db, trigger, setsScope, started, false, result, err)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
 }
 
-// SyncAllSets fetches all sets from OPTCG API and caches them
-func (s *SetSyncService) SyncAllSets() (int, error) {
-	log.Println("[SYNC] Fetching sets from OPTCG API...")
+// syncAllSets does the work of SyncAllSets. Failures after the source
+// answered return the result so far along with the error.
+func (s *SetSyncService) syncAllSets(force bool) (*SyncResult, error) {
+	log.Printf("[SYNC] Fetching sets from %s...", s.source.Name())
 
-	// Fetch from OPTCG API
-	resp, err := s.httpClient.Get("https://optcgapi.com/api/allSets/")
+	state, err := s.db.GetSyncState(setsScope)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch sets from API: %w", err)
+		return nil, fmt.Errorf("failed to fetch sync state: %w", err)
 	}
-	defer resp.Body.Close()
+	cached, err := s.db.GetAllSets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cached sets: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code from OPTCG API: %d", resp.StatusCode)
+	list, err := s.source.ListSets(ctx, syncValidators(state, len(cached), force))
+	if err != nil {
+		return nil, err
 	}
 
-	var sets []OPTCGSet
-	if err := json.NewDecoder(resp.Body).Decode(&sets); err != nil {
-		return 0, fmt.Errorf("failed to decode sets response: %w", err)
+	now := time.Now()
+	result := &SyncResult{Scope: setsScope, HTTPStatus: list.HTTPStatus, SyncedAt: now}
+	if list.NotModified && state != nil {
+		result.NotModified = true
+		result.Unchanged = len(cached)
+		log.Printf("[SYNC] Sets not modified (%d cached)", len(cached))
+		if err := s.db.MarkSetsSynced(now); err != nil {
+			return result, fmt.Errorf("failed to mark sets synced: %w", err)
+		}
+		return result, saveSyncState(s.db, nextSyncState(state, setsScope, list.Validators, state.ContentHash, len(cached), now))
 	}
 
-	// Upsert into database
-	for _, set := range sets {
+	names := make(map[string]string, len(cached))
+	for _, set := range cached {
+		names[set.SetID] = set.SetName
+	}
+	for _, set := range list.Sets {
+		name, ok := names[set.SetID]
+		switch {
+		case !ok:
+			result.Added++
+		case name != set.SetName:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+		// Unchanged sets are written too, to move their last_synced
 		if err := s.db.UpsertSet(set.SetID, set.SetName); err != nil {
-			return 0, fmt.Errorf("failed to upsert set %s: %w", set.SetID, err)
+			return result, fmt.Errorf("failed to upsert set %s: %w", set.SetID, err)
 		}
 	}
 
-	log.Printf("[SYNC] Successfully synced %d sets", len(sets))
-	return len(sets), nil
+	hash := setListVersion(list.Sets)
+	if err := saveSyncState(s.db, nextSyncState(state, setsScope, list.Validators, hash, len(cached)+result.Added, now)); err != nil {
+		return result, err
+	}
+
+	log.Printf("[SYNC] Successfully synced %d sets (%d added, %d updated, %d unchanged)",
+		result.Total(), result.Added, result.Updated, result.Unchanged)
+	return result, nil
 }
 
 // StartAutoSync starts a background goroutine that syncs sets periodically
@@ -65,7 +105,7 @@ func (s *SetSyncService) StartAutoSync(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	go func() {
 		for range ticker.C {
-			if _, err := s.SyncAllSets(); err != nil {
+			if _, err := s.SyncAllSets(SyncTriggerTicker, false); err != nil {
 				log.Printf("[SYNC] Auto-sync failed: %v", err)
 			}
 		}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"card-separator/cardsource"
+	"card-separator/database"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// syncTimeout bounds one sync's requests to the card source
+const syncTimeout = 30 * time.Second
+
+// What started a sync, recorded with each sync run
+const (
+	SyncTriggerStartup = "startup"
+	SyncTriggerTicker  = "ticker"
+	SyncTriggerAPI     = "api"
+)
+
+// SyncTriggers lists every sync trigger
+var SyncTriggers = []string{SyncTriggerStartup, SyncTriggerTicker, SyncTriggerAPI}
+
+// setsScope is the sync state scope of the set list
+const setsScope = "sets"
+
+// cardsScope returns the sync state scope of a set's cards
+func cardsScope(setID string) string {
+	return "cards/" + setID
+}
+
+// SyncResult reports what a sync found and changed
+type SyncResult struct {
+	Scope       string    `json:"scope"`
+	NotModified bool      `json:"not_modified"` // The source reported no change, nothing was downloaded
+	DryRun      bool      `json:"dry_run,omitempty"`
+	Added       int       `json:"added"`
+	Updated     int       `json:"updated"`
+	Unchanged   int       `json:"unchanged"`
+	Removed     int       `json:"removed"` // Cards retired as no longer listed by the source, 0 for sets
+	HTTPStatus  int       `json:"http_status,omitempty"`
+	SyncedAt    time.Time `json:"synced_at"`
+}
+
+// Total returns how many items the source lists
+func (r *SyncResult) Total() int {
+	return r.Added + r.Updated + r.Unchanged
+}
+
+// syncValidators returns the validators to make a sync conditional on.
+// The request is unconditional when forced, when nothing was synced before,
+// or when the cache no longer holds what was synced so it must be refilled.
+func syncValidators(state *database.SyncState, cached int, force bool) cardsource.Validators {
+	if force || state == nil || state.ItemCount != cached {
+		return cardsource.Validators{}
+	}
+	return cardsource.Validators{ETag: state.ETag, LastModified: state.LastModified}
+}
+
+// nextSyncState returns the state to save after a sync of scope that left
+// cached items in the cache
+func nextSyncState(prev *database.SyncState, scope string, v cardsource.Validators, hash string, cached int, now time.Time) *database.SyncState {
+	state := &database.SyncState{
+		Scope:        scope,
+		ETag:         v.ETag,
+		LastModified: v.LastModified,
+		ContentHash:  hash,
+		ItemCount:    cached,
+		CheckedAt:    now,
+		ChangedAt:    now,
+	}
+	if prev != nil && prev.ContentHash == hash {
+		state.ChangedAt = prev.ChangedAt
+	}
+	return state
+}
+
+// setListVersion hashes a set list in order
+func setListVersion(sets []cardsource.Set) string {
+	data, _ := json.Marshal(sets)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// saveSyncState stores the state of a finished sync
+func saveSyncState(db *database.DB, state *database.SyncState) error {
+	if err := db.UpsertSyncState(state); err != nil {
+		return fmt.Errorf("failed to save sync state for %s: %w", state.Scope, err)
+	}
+	return nil
+}
+
+// recordSyncRun stores the outcome of a sync of scope started at started.
+// result may be set alongside syncErr when the sync failed after hearing
+// from the source. A failure to record is logged rather than failing the
+// sync.
+func recordSyncRun(db *database.DB, trigger, scope string, started time.Time, dryRun bool, result *SyncResult, syncErr error) {
+	run := &database.SyncRun{
+		Trigger:    trigger,
+		Scope:      scope,
+		StartedAt:  started,
+		FinishedAt: time.Now(),
+		DryRun:     dryRun,
+	}
+	if result != nil {
+		run.HTTPStatus = result.HTTPStatus
+	}
+	if syncErr != nil {
+		run.Error = syncErr.Error()
+		if run.HTTPStatus == 0 {
+			run.HTTPStatus = cardsource.HTTPStatus(syncErr)
+		}
+	} else {
+		run.NotModified = result.NotModified
+		run.Added = result.Added
+		run.Updated = result.Updated
+		run.Unchanged = result.Unchanged
+		run.Removed = result.Removed
+	}
+	if err := db.InsertSyncRun(run); err != nil {
+		log.Printf("[SYNC] Warning: failed to record %s sync run of %s: %v", trigger, scope, err)
+	}
+}
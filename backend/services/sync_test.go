@@ -0,0 +1,218 @@
+package services
+
+import (
+	"card-separator/cardsource"
+	"card-separator/database"
+	"card-separator/optcgstub"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// syncFixture is a stub OPTCG API with sync services reading from it into
+// a fresh database
+type syncFixture struct {
+	stub  *optcgstub.Server
+	db    *database.DB
+	sets  *SetSyncService
+	cards *CardSyncService
+}
+
+func newSyncFixture(t *testing.T) *syncFixture {
+	t.Helper()
+	return newSyncFixtureFS(t, optcgstub.Fixtures())
+}
+
+// newSyncFixtureFS is newSyncFixture with the stub serving fixtures
+func newSyncFixtureFS(t *testing.T, fixtures fs.FS) *syncFixture {
+	t.Helper()
+	stub := optcgstub.New(fixtures)
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "cards.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	source := cardsource.NewOPTCG(srv.URL + "/api")
+	return &syncFixture{
+		stub:  stub,
+		db:    db,
+		sets:  NewSetSyncService(db, source),
+		cards: NewCardSyncService(db, source),
+	}
+}
+
+// lastRun returns the newest recorded sync run
+func (f *syncFixture) lastRun(t *testing.T) database.SyncRun {
+	t.Helper()
+	runs, err := f.db.GetSyncRuns(database.SyncRunFilter{Limit: 1})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("no sync run recorded: %v", err)
+	}
+	return runs[0]
+}
+
+func TestSyncFromStub(t *testing.T) {
+	f := newSyncFixture(t)
+
+	sets, err := f.sets.SyncAllSets(SyncTriggerAPI, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sets.HTTPStatus != http.StatusOK || sets.NotModified {
+		t.Errorf("set sync status %d, not modified %v", sets.HTTPStatus, sets.NotModified)
+	}
+	if sets.Added == 0 || sets.Added != sets.Total() {
+		t.Errorf("first set sync should add every set: %+v", sets)
+	}
+
+	cards, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards.HTTPStatus != http.StatusOK || cards.Added != 12 {
+		t.Errorf("card sync = %+v, want 12 cards added with 200", cards)
+	}
+	if n, _ := f.db.CountCardsBySet("OP-01"); n != 12 {
+		t.Errorf("cached %d cards, want 12", n)
+	}
+	if run := f.lastRun(t); run.Scope != "cards/OP-01" || run.HTTPStatus != http.StatusOK || run.Added != 12 || run.Error != "" {
+		t.Errorf("recorded run = %+v", run)
+	}
+}
+
+func TestSyncNotModifiedByETag(t *testing.T) {
+	f := newSyncFixture(t)
+	if _, err := f.sets.SyncAllSets(SyncTriggerAPI, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	sets, err := f.sets.SyncAllSets(SyncTriggerTicker, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sets.NotModified || sets.HTTPStatus != http.StatusNotModified {
+		t.Errorf("second set sync = %+v, want 304 not modified", sets)
+	}
+
+	cards, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cards.NotModified || cards.HTTPStatus != http.StatusNotModified || cards.Unchanged != 12 {
+		t.Errorf("second card sync = %+v, want 304 with 12 unchanged", cards)
+	}
+	if run := f.lastRun(t); !run.NotModified || run.HTTPStatus != http.StatusNotModified {
+		t.Errorf("recorded run = %+v", run)
+	}
+
+	// Forcing skips the conditional request
+	cards, err = f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards.NotModified || cards.HTTPStatus != http.StatusOK || cards.Unchanged != 12 {
+		t.Errorf("forced card sync = %+v, want 200 with 12 unchanged", cards)
+	}
+}
+
+func TestSyncInjectedServerError(t *testing.T) {
+	f := newSyncFixture(t)
+	if _, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", false, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.stub.SetFaults(optcgstub.Faults{ErrorRate: 1, ErrorStatus: http.StatusBadGateway}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", true, false)
+	if err == nil {
+		t.Fatal("expected the injected 502 to fail the sync")
+	}
+	if got := cardsource.HTTPStatus(err); got != http.StatusBadGateway {
+		t.Errorf("error status = %d, want 502: %v", got, err)
+	}
+	if n, _ := f.db.CountCardsBySet("OP-01"); n != 12 {
+		t.Errorf("failed sync left %d cards, want 12", n)
+	}
+	if run := f.lastRun(t); run.HTTPStatus != http.StatusBadGateway || run.Error == "" {
+		t.Errorf("recorded run = %+v", run)
+	}
+
+	if _, err := f.sets.SyncAllSets(SyncTriggerTicker, false); cardsource.HTTPStatus(err) != http.StatusBadGateway {
+		t.Errorf("set sync error = %v, want a 502", err)
+	}
+}
+
+func TestSyncMalformedJSON(t *testing.T) {
+	f := newSyncFixture(t)
+	if _, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", false, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.stub.SetFaults(optcgstub.Faults{MalformedRate: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-01", true, false)
+	if err == nil || !strings.Contains(err.Error(), "decode") {
+		t.Fatalf("expected a decode error, got %v", err)
+	}
+	if n, _ := f.db.CountCardsBySet("OP-01"); n != 12 {
+		t.Errorf("malformed response left %d cards, want 12", n)
+	}
+	if run := f.lastRun(t); run.Error == "" {
+		t.Errorf("recorded run = %+v, want an error", run)
+	}
+
+	if _, err := f.sets.SyncAllSets(SyncTriggerAPI, true); err == nil {
+		t.Error("expected malformed set list to fail the sync")
+	}
+}
+
+func TestSyncDuplicateCardIDs(t *testing.T) {
+	// The source lists OP09-001 twice, as it does for parallel arts
+	f := newSyncFixtureFS(t, fstest.MapFS{
+		"sets/OP-09.json": {Data: []byte(`[
+			{"card_set_id": "OP09-001", "card_name": "Shanks", "set_id": "OP-09", "set_name": "Emperors in the New World"},
+			{"card_set_id": "OP09-002", "card_name": "Uta", "set_id": "OP-09", "set_name": "Emperors in the New World"},
+			{"card_set_id": "OP09-001", "card_name": "Shanks (Parallel)", "set_id": "OP-09", "set_name": "Emperors in the New World"}
+		]`)},
+	})
+
+	cards, err := f.cards.SyncSetCards(SyncTriggerAPI, "OP-09", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards.Added != 2 || cards.Total() != 2 {
+		t.Errorf("first card sync = %+v, want 2 cards added", cards)
+	}
+	stored, err := f.db.GetCardsByIDs([]string{"OP09-001"})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("OP09-001 not cached: %v", err)
+	}
+	if stored[0].CardName != "Shanks (Parallel)" {
+		t.Errorf("cached %q, want the last listed entry", stored[0].CardName)
+	}
+
+	// The cache matches the payload, so nothing counts as updated
+	cards, err = f.cards.SyncSetCards(SyncTriggerAPI, "OP-09", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards.Unchanged != 2 || cards.Updated != 0 || cards.Added != 0 {
+		t.Errorf("forced card sync = %+v, want 2 unchanged", cards)
+	}
+}
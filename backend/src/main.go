@@ -1,9 +1,11 @@
 package main
 
 import (
+	"card-separator/cardsource"
 	"card-separator/config"
 	"card-separator/database"
 	"card-separator/handlers"
+	"card-separator/render"
 	"card-separator/services"
 	"card-separator/storage"
 	"context"
@@ -56,29 +58,60 @@ func main() {
 	}
 	log.Println("✅ MinIO storage initialized")
 
+	// Initialize card source
+	cardSource, err := cardsource.New(cfg.CardSource, cfg.OPTCGAPIURL, cfg.CardSourceDir)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize card source: %v", err)
+	}
+	log.Printf("✅ Card source: %s", cardSource.Name())
+
 	// Initialize services
 	imageService := services.NewImageService(db, minioStorage, cfg.ImageSizes)
-	setSyncService := services.NewSetSyncService(db)
-	cardSyncService := services.NewCardSyncService(db)
+	setSyncService := services.NewSetSyncService(db, cardSource)
+	cardSyncService := services.NewCardSyncService(db, cardSource)
+	fontService := services.NewFontService(db, minioStorage)
+	profileService := services.NewProfileService(db)
+	layoutService := services.NewLayoutService(db, fontService, profileService)
+	jobService := services.NewPrintJobService(db, layoutService)
+	pdfRenderer := render.NewPDFRenderer(imageService, fontService, render.DefaultStyle())
+	renderQueue := services.NewRenderQueue(layoutService, jobService, pdfRenderer, minioStorage, cfg.RenderTimeout, cfg.RenderTTL)
 	log.Println("✅ Services initialized")
 
 	// Auto-sync on startup
 	if cfg.AutoSyncOnStartup {
 		log.Println("🔄 Running initial set sync...")
-		if count, err := setSyncService.SyncAllSets(); err != nil {
+		if result, err := setSyncService.SyncAllSets(services.SyncTriggerStartup, false); err != nil {
 			log.Printf("⚠️  Initial sync failed: %v", err)
 		} else {
-			log.Printf("✅ Synced %d sets on startup", count)
+			log.Printf("✅ Synced %d sets on startup", result.Total())
 		}
 	}
 
 	// Start background sync worker
 	setSyncService.StartAutoSync(cfg.SetSyncInterval)
 
+	// Start background render workers
+	renderQueue.Start(cfg.RenderWorkers)
+
 	// Initialize handlers
 	imageHandler := handlers.NewImageHandler(imageService)
 	setHandler := handlers.NewSetHandler(db, setSyncService)
 	cardHandler := handlers.NewCardHandler(db, cardSyncService)
+	layoutHandler := handlers.NewLayoutHandler(layoutService)
+	printerHandler := handlers.NewPrinterHandler(db, layoutService)
+	templateHandler := handlers.NewTemplateHandler(db)
+	fontHandler := handlers.NewFontHandler(fontService)
+	presetHandler := handlers.NewPresetHandler(db)
+	exportHandler := handlers.NewExportHandler(
+		layoutService,
+		pdfRenderer,
+		render.NewSVGRenderer(imageService, fontService, render.DefaultStyle()),
+		render.NewPNGRenderer(imageService, fontService, render.DefaultStyle()),
+	)
+	jobHandler := handlers.NewJobHandler(jobService, layoutService, pdfRenderer)
+	renderHandler := handlers.NewRenderHandler(renderQueue, layoutService)
+	profileHandler := handlers.NewProfileHandler(profileService)
+	syncHandler := handlers.NewSyncHandler(db)
 	log.Println("✅ Handlers initialized")
 
 	// Setup router
@@ -86,7 +119,7 @@ func main() {
 	api := r.PathPrefix("/api").Subrouter()
 
 	// Health endpoint
-	api.HandleFunc("/health", handleHealth(db, minioStorage)).Methods("GET")
+	api.HandleFunc("/health", handleHealth(db, minioStorage, 2*cfg.SetSyncInterval)).Methods("GET")
 
 	// Image endpoints
 	api.HandleFunc("/images/{size}", imageHandler.GetImage).Methods("GET")
@@ -96,10 +129,65 @@ func main() {
 	api.HandleFunc("/sets", setHandler.ListSets).Methods("GET")
 	api.HandleFunc("/sets/sync", setHandler.SyncSets).Methods("POST")
 
+	// Sync history endpoints
+	api.HandleFunc("/sync/runs", syncHandler.ListRuns).Methods("GET")
+
 	// Card endpoints
 	api.HandleFunc("/cards", cardHandler.SearchCards).Methods("GET")
 	api.HandleFunc("/sets/{set_id}/cards", cardHandler.GetSetCards).Methods("GET")
 	api.HandleFunc("/sets/{set_id}/sync", cardHandler.SyncSetCards).Methods("POST")
+	api.HandleFunc("/sets/{set_id}/separators.pdf", exportHandler.ExportSetPDF).Methods("GET", "POST")
+
+	// Layout endpoints
+	api.HandleFunc("/layouts", layoutHandler.CreateLayout).Methods("POST")
+	api.HandleFunc("/layouts/{id}", layoutHandler.GetLayout).Methods("GET")
+	api.HandleFunc("/layouts/{id}/pages/{page:[0-9]+}.svg", exportHandler.ExportPageSVG).Methods("GET")
+	api.HandleFunc("/layouts/{id}/pages/{page:[0-9]+}.png", exportHandler.ExportPagePNG).Methods("GET")
+	api.HandleFunc("/layouts/{id}/preview.png", exportHandler.ExportPreviewPNG).Methods("GET")
+	api.HandleFunc("/layouts/{id}/sheets/{sheet:[0-9]+}/cut.svg", exportHandler.ExportCutSVG).Methods("GET")
+	api.HandleFunc("/layouts/{id}/sheets/{sheet:[0-9]+}/cut.dxf", exportHandler.ExportCutDXF).Methods("GET")
+	api.HandleFunc("/imposition", layoutHandler.Impose).Methods("GET", "POST")
+
+	// Print job endpoints
+	api.HandleFunc("/jobs", jobHandler.ListJobs).Methods("GET")
+	api.HandleFunc("/jobs", jobHandler.CreateJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}", jobHandler.GetJob).Methods("GET")
+	api.HandleFunc("/jobs/{id}", jobHandler.DeleteJob).Methods("DELETE")
+	api.HandleFunc("/jobs/{id}/separators.pdf", jobHandler.ExportJobPDF).Methods("GET")
+
+	// Render queue endpoints
+	api.HandleFunc("/renders", renderHandler.QueueRender).Methods("POST")
+	api.HandleFunc("/renders/{id}", renderHandler.GetRender).Methods("GET")
+	api.HandleFunc("/renders/{id}/events", renderHandler.StreamRender).Methods("GET")
+	api.HandleFunc("/renders/{id}/file", renderHandler.DownloadRender).Methods("GET")
+
+	// Printer profile endpoints
+	api.HandleFunc("/printers", printerHandler.ListPrinters).Methods("GET")
+	api.HandleFunc("/printers/{name}", printerHandler.GetPrinter).Methods("GET")
+	api.HandleFunc("/printers/{name}", printerHandler.SavePrinter).Methods("PUT")
+	api.HandleFunc("/printers/{name}", printerHandler.DeletePrinter).Methods("DELETE")
+	api.HandleFunc("/calibration.pdf", printerHandler.CalibrationPDF).Methods("GET")
+	api.HandleFunc("/templates/preview", templateHandler.PreviewTemplate).Methods("POST")
+
+	// Page size and separator profile endpoints
+	api.HandleFunc("/profiles", profileHandler.ListProfiles).Methods("GET")
+	api.HandleFunc("/profiles/{kind:pages|cards}/{name}", profileHandler.GetProfile).Methods("GET")
+	api.HandleFunc("/profiles/{kind:pages|cards}/{name}", profileHandler.SaveProfile).Methods("PUT")
+	api.HandleFunc("/profiles/{kind:pages|cards}/{name}", profileHandler.DeleteProfile).Methods("DELETE")
+
+	// Font endpoints
+	api.HandleFunc("/fonts", fontHandler.ListFonts).Methods("GET")
+	api.HandleFunc("/fonts/{name}", fontHandler.GetFont).Methods("GET")
+	api.HandleFunc("/fonts/{name}", fontHandler.SaveFont).Methods("PUT")
+	api.HandleFunc("/fonts/{name}", fontHandler.DeleteFont).Methods("DELETE")
+
+	// Preset endpoints
+	api.HandleFunc("/presets", presetHandler.ListPresets).Methods("GET")
+	api.HandleFunc("/presets", presetHandler.CreatePreset).Methods("POST")
+	api.HandleFunc("/presets/{name}", presetHandler.GetPreset).Methods("GET")
+	api.HandleFunc("/presets/{name}", presetHandler.SavePreset).Methods("PUT")
+	api.HandleFunc("/presets/{name}", presetHandler.DeletePreset).Methods("DELETE")
+	api.HandleFunc("/presets/{name}/export", presetHandler.ExportPreset).Methods("GET")
 
 	// Cache stats endpoint
 	api.HandleFunc("/cache/stats", handleCacheStats(db)).Methods("GET")
@@ -107,7 +195,7 @@ func main() {
 	// CORS configuration
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"}, // TODO: Restrict in production
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
 		AllowCredentials: true,
 		MaxAge:           86400,
@@ -124,7 +212,31 @@ func main() {
 	log.Println("   - POST /api/sets/sync")
 	log.Println("   - GET  /api/sets/{set_id}/cards")
 	log.Println("   - POST /api/sets/{set_id}/sync")
+	log.Println("   - GET  /api/sync/runs?trigger=&scope=&status=&since=")
+	log.Println("   - GET  /api/sets/{set_id}/separators.pdf")
 	log.Println("   - GET  /api/cards?color=&type=&rarity=")
+	log.Println("   - POST /api/layouts")
+	log.Println("   - GET  /api/layouts/{id}")
+	log.Println("   - GET  /api/layouts/{id}/pages/{n}.svg")
+	log.Println("   - GET  /api/layouts/{id}/pages/{n}.png?dpi=300")
+	log.Println("   - GET  /api/layouts/{id}/preview.png")
+	log.Println("   - GET  /api/layouts/{id}/sheets/{sheet}/cut.svg|dxf")
+	log.Println("   - GET  /api/imposition")
+	log.Println("   - POST /api/jobs")
+	log.Println("   - GET  /api/jobs/{id}/separators.pdf")
+	log.Println("   - POST /api/renders")
+	log.Println("   - GET  /api/renders/{id}/events")
+	log.Println("   - GET  /api/printers")
+	log.Println("   - PUT  /api/printers/{name}")
+	log.Println("   - GET  /api/calibration.pdf?flip_edge=&printer=")
+	log.Println("   - POST /api/templates/preview")
+	log.Println("   - GET  /api/profiles")
+	log.Println("   - PUT  /api/profiles/{pages|cards}/{name}")
+	log.Println("   - GET  /api/fonts")
+	log.Println("   - PUT  /api/fonts/{name}")
+	log.Println("   - GET  /api/presets")
+	log.Println("   - POST /api/presets")
+	log.Println("   - GET  /api/presets/{name}/export")
 	log.Println("   - GET  /api/cache/stats")
 
 	srv := &http.Server{
@@ -141,8 +253,10 @@ func main() {
 	}
 }
 
-// handleHealth provides a health check endpoint
-func handleHealth(db *database.DB, storage *storage.MinIOStorage) http.HandlerFunc {
+// handleHealth provides a health check endpoint. Card data is reported
+// stale once the last successful sync is older than staleAfter, which does
+// not degrade the status since the cached data still serves.
+func handleHealth(db *database.DB, storage *storage.MinIOStorage, staleAfter time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		health := map[string]interface{}{
 			"status":    "ok",
@@ -168,6 +282,21 @@ func handleHealth(db *database.DB, storage *storage.MinIOStorage) http.HandlerFu
 			health["minio"] = "ok"
 		}
 
+		// Check card data freshness
+		sync := map[string]interface{}{}
+		if run, err := db.GetLastSuccessfulSyncRun(); err != nil {
+			sync["error"] = err.Error()
+		} else if run == nil {
+			sync["last_success"] = nil
+			sync["stale"] = true
+		} else {
+			age := time.Since(run.FinishedAt)
+			sync["last_success"] = run
+			sync["age_seconds"] = int64(age.Seconds())
+			sync["stale"] = age > staleAfter
+		}
+		health["sync"] = sync
+
 		w.Header().Set("Content-Type", "application/json")
 		if health["status"] == "degraded" {
 			w.WriteHeader(http.StatusServiceUnavailable)
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -104,6 +105,26 @@ func (m *MinIOStorage) Delete(ctx context.Context, objectKey string) error {
 	return m.client.RemoveObject(ctx, m.bucket, objectKey, minio.RemoveObjectOptions{})
 }
 
+// RemoveOlderThan deletes objects under prefix last modified more than age
+// ago, returning how many were removed
+func (m *MinIOStorage) RemoveOlderThan(ctx context.Context, prefix string, age time.Duration) (int, error) {
+	cutoff := time.Now().Add(-age)
+	removed := 0
+	for obj := range m.client.ListObjects(ctx, m.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return removed, obj.Err
+		}
+		if obj.LastModified.After(cutoff) {
+			continue
+		}
+		if err := m.Delete(ctx, obj.Key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
 // Ping checks if MinIO is accessible
 func (m *MinIOStorage) Ping(ctx context.Context) error {
 	_, err := m.client.BucketExists(ctx, m.bucket)
@@ -0,0 +1,314 @@
+// Package tabtemplate renders separator tab text from card fields.
+//
+// Templates are plain text with placeholders in braces:
+//
+//	{card_name}                   a card field, by its JSON name
+//	{card_name|upper}             filters: upper, lower, truncate:N
+//	{if card_cost}...{else}...{end} conditionals on a field being set
+//	{{ and }}                     literal braces
+//
+// The placeholders of the browser editor ({name}, {id}, {cost}, {setId},
+// {type} and {rarity}) are accepted as aliases.
+package tabtemplate
+
+import (
+	"card-separator/database"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fields maps each card field, by JSON name, to its value as text
+var Fields = map[string]func(database.Card) string{
+	"card_set_id":    func(c database.Card) string { return c.CardSetID },
+	"card_name":      func(c database.Card) string { return c.CardName },
+	"set_id":         func(c database.Card) string { return c.SetID },
+	"set_name":       func(c database.Card) string { return c.SetName },
+	"card_image_url": func(c database.Card) string { return c.CardImageURL },
+	"card_color":     func(c database.Card) string { return c.CardColor },
+	"card_type":      func(c database.Card) string { return c.CardType },
+	"card_cost":      func(c database.Card) string { return strconv.Itoa(c.CardCost) },
+	"card_power":     func(c database.Card) string { return strconv.Itoa(c.CardPower) },
+	"rarity":         func(c database.Card) string { return c.Rarity },
+	"attribute":      func(c database.Card) string { return c.Attribute },
+	"card_text":      func(c database.Card) string { return c.CardText },
+}
+
+// aliases maps the browser editor's placeholders onto card fields
+var aliases = map[string]string{
+	"name":  "card_name",
+	"id":    "card_set_id",
+	"cost":  "card_cost",
+	"setId": "set_id",
+	"type":  "card_type",
+}
+
+// Ellipsis marks text shortened by the truncate filter
+const Ellipsis = "…"
+
+// Error reports a problem in a template and where it is
+type Error struct {
+	Offset int    `json:"offset"` // Byte offset of the offending placeholder
+	Msg    string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("template offset %d: %s", e.Offset, e.Msg)
+}
+
+// Template is a parsed tab text template
+type Template struct {
+	src   string
+	nodes []node
+}
+
+// FieldNames returns the card field names templates can use, sorted
+func FieldNames() []string {
+	names := make([]string, 0, len(Fields))
+	for name := range Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the value getter for a field name or alias
+func Lookup(name string) (func(database.Card) string, bool) {
+	if canonical, ok := aliases[name]; ok {
+		name = canonical
+	}
+	get, ok := Fields[name]
+	return get, ok
+}
+
+// Parse parses a template, rejecting unknown fields and filters and
+// unbalanced conditionals
+func Parse(src string) (*Template, error) {
+	p := &parser{src: src}
+	nodes, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Template{src: src, nodes: nodes}, nil
+}
+
+// String returns the template source
+func (t *Template) String() string {
+	return t.src
+}
+
+// Execute renders the template for a card
+func (t *Template) Execute(card database.Card) string {
+	var b strings.Builder
+	execNodes(t.nodes, card, &b)
+	return b.String()
+}
+
+// node is one parsed piece of a template
+type node interface {
+	exec(card database.Card, b *strings.Builder)
+}
+
+type textNode string
+
+func (n textNode) exec(_ database.Card, b *strings.Builder) {
+	b.WriteString(string(n))
+}
+
+type fieldNode struct {
+	get     func(database.Card) string
+	filters []func(string) string
+}
+
+func (n fieldNode) exec(card database.Card, b *strings.Builder) {
+	v := n.get(card)
+	for _, f := range n.filters {
+		v = f(v)
+	}
+	b.WriteString(v)
+}
+
+type ifNode struct {
+	get        func(database.Card) string
+	then, els  []node
+	elseOffset int // -1 until {else} is seen
+}
+
+func (n *ifNode) exec(card database.Card, b *strings.Builder) {
+	if v := n.get(card); v != "" && v != "0" {
+		execNodes(n.then, card, b)
+	} else {
+		execNodes(n.els, card, b)
+	}
+}
+
+func execNodes(nodes []node, card database.Card, b *strings.Builder) {
+	for _, n := range nodes {
+		n.exec(card, b)
+	}
+}
+
+// parser turns template source into nodes
+type parser struct {
+	src string
+}
+
+// frame is an open {if} block
+type frame struct {
+	node   *ifNode
+	offset int
+	parent []node
+}
+
+func (p *parser) parse() ([]node, error) {
+	var (
+		nodes []node
+		stack []frame
+		text  strings.Builder
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(p.src); {
+		switch {
+		case strings.HasPrefix(p.src[i:], "{{"):
+			text.WriteByte('{')
+			i += 2
+			continue
+		case strings.HasPrefix(p.src[i:], "}}"):
+			text.WriteByte('}')
+			i += 2
+			continue
+		case p.src[i] != '{':
+			text.WriteByte(p.src[i])
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(p.src[i:], '}')
+		if end < 0 {
+			return nil, &Error{Offset: i, Msg: "unclosed placeholder, missing }"}
+		}
+		body := strings.TrimSpace(p.src[i+1 : i+end])
+		offset := i
+		i += end + 1
+		flush()
+
+		switch {
+		case strings.HasPrefix(body, "if "):
+			get, err := p.field(strings.TrimSpace(body[3:]), offset)
+			if err != nil {
+				return nil, err
+			}
+			n := &ifNode{get: get, elseOffset: -1}
+			stack = append(stack, frame{node: n, offset: offset, parent: nodes})
+			nodes = nil
+		case body == "else":
+			if len(stack) == 0 {
+				return nil, &Error{Offset: offset, Msg: "{else} without {if}"}
+			}
+			top := &stack[len(stack)-1]
+			if top.node.elseOffset >= 0 {
+				return nil, &Error{Offset: offset, Msg: "duplicate {else}"}
+			}
+			top.node.then, top.node.elseOffset = nodes, offset
+			nodes = nil
+		case body == "end":
+			if len(stack) == 0 {
+				return nil, &Error{Offset: offset, Msg: "{end} without {if}"}
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top.node.elseOffset >= 0 {
+				top.node.els = nodes
+			} else {
+				top.node.then = nodes
+			}
+			nodes = append(top.parent, top.node)
+		default:
+			n, err := p.placeholder(body, offset)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	flush()
+
+	if len(stack) > 0 {
+		return nil, &Error{Offset: stack[len(stack)-1].offset, Msg: "{if} without {end}"}
+	}
+	return nodes, nil
+}
+
+// placeholder parses "field|filter|filter:arg"
+func (p *parser) placeholder(body string, offset int) (node, error) {
+	parts := strings.Split(body, "|")
+	get, err := p.field(strings.TrimSpace(parts[0]), offset)
+	if err != nil {
+		return nil, err
+	}
+
+	n := fieldNode{get: get}
+	for _, spec := range parts[1:] {
+		f, err := parseFilter(strings.TrimSpace(spec), offset)
+		if err != nil {
+			return nil, err
+		}
+		n.filters = append(n.filters, f)
+	}
+	return n, nil
+}
+
+// field resolves a field name or alias
+func (p *parser) field(name string, offset int) (func(database.Card) string, error) {
+	if name == "" {
+		return nil, &Error{Offset: offset, Msg: "empty placeholder"}
+	}
+	get, ok := Lookup(name)
+	if !ok {
+		return nil, &Error{Offset: offset, Msg: fmt.Sprintf("unknown placeholder {%s}, expected one of %s",
+			name, strings.Join(FieldNames(), ", "))}
+	}
+	return get, nil
+}
+
+// parseFilter parses one filter spec such as "upper" or "truncate:12"
+func parseFilter(spec string, offset int) (func(string) string, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch name {
+	case "upper":
+		if !hasArg {
+			return strings.ToUpper, nil
+		}
+	case "lower":
+		if !hasArg {
+			return strings.ToLower, nil
+		}
+	case "truncate":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return nil, &Error{Offset: offset, Msg: fmt.Sprintf("truncate needs a positive length, got %q", arg)}
+		}
+		return func(s string) string { return Truncate(s, n) }, nil
+	default:
+		return nil, &Error{Offset: offset, Msg: fmt.Sprintf("unknown filter %q, expected upper, lower or truncate:N", name)}
+	}
+	return nil, &Error{Offset: offset, Msg: fmt.Sprintf("filter %q takes no argument", name)}
+}
+
+// Truncate shortens s to at most n characters, ending with an ellipsis
+// when anything was cut
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:n-1]), " ") + Ellipsis
+}